@@ -0,0 +1,156 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	pb "github.com/samsapti/sec1-handin-02/grpc"
+	"github.com/samsapti/sec1-handin-02/protocol"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+type identityKey struct{}
+
+// canonical returns the form of a player name that identifies the player.
+// Names are not case sensitive, so "Bob" and "bob" are the same player, and
+// players are keyed, compared and ordered by this form only.
+func canonical(player string) string {
+	return strings.ToLower(player)
+}
+
+// identityOf returns the authenticated player of an incoming RPC.
+func identityOf(ctx context.Context) string {
+	identity, _ := ctx.Value(identityKey{}).(string)
+	return identity
+}
+
+// authenticate returns the player named by the certificate of the caller, in
+// canonical form. It rejects ourselves, and once the session has started,
+// anyone outside of it.
+func (s *server) authenticate(ctx context.Context) (string, error) {
+	identity, err := peerIdentity(ctx)
+	if err != nil {
+		return "", status.Errorf(codes.PermissionDenied, "%s", err)
+	}
+	if identity == canonical(*name) {
+		return "", status.Errorf(codes.PermissionDenied, "%s may not play against themselves", identity)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.session == nil || s.peers[identity] {
+		return identity, nil
+	}
+
+	return "", status.Errorf(codes.PermissionDenied, "%s is not in session %x", identity, s.session)
+}
+
+func (s *server) unaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	identity, err := s.authenticate(ctx)
+	if err != nil {
+		log.Printf("%s rejects %s: %s\n", *name, info.FullMethod, err)
+		return nil, err
+	}
+	log.Printf("%s receives %s from %s\n", *name, describe(req), identity)
+
+	return handler(context.WithValue(ctx, identityKey{}, identity), req)
+}
+
+func (s *server) streamInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	identity, err := s.authenticate(ss.Context())
+	if err != nil {
+		log.Printf("%s rejects %s: %s\n", *name, info.FullMethod, err)
+		return err
+	}
+
+	return handler(srv, &authServerStream{
+		ServerStream: ss,
+		ctx:          context.WithValue(ss.Context(), identityKey{}, identity),
+		identity:     identity,
+	})
+}
+
+// authServerStream logs the authenticated peer of every message it receives.
+type authServerStream struct {
+	grpc.ServerStream
+	ctx      context.Context
+	identity string
+}
+
+func (ss *authServerStream) Context() context.Context {
+	return ss.ctx
+}
+
+func (ss *authServerStream) RecvMsg(m interface{}) error {
+	if err := ss.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	log.Printf("%s receives %s from %s\n", *name, describe(m), ss.identity)
+
+	return nil
+}
+
+// unaryClientInterceptor checks that the server of an outgoing RPC presented
+// a certificate naming a player, and logs who the response came from.
+func unaryClientInterceptor(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	var p peer.Peer
+	if err := invoker(ctx, method, req, reply, cc, append(opts, grpc.Peer(&p))...); err != nil {
+		return err
+	}
+
+	identity, err := peerIdentity(peer.NewContext(ctx, &p))
+	if err != nil {
+		return status.Errorf(codes.PermissionDenied, "%s", err)
+	}
+	log.Printf("%s receives %s from %s\n", *name, describe(reply), identity)
+
+	return nil
+}
+
+func streamClientInterceptor(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	cs, err := streamer(ctx, desc, cc, method, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	identity, err := peerIdentity(cs.Context())
+	if err != nil {
+		return nil, status.Errorf(codes.PermissionDenied, "%s", err)
+	}
+
+	return &authClientStream{ClientStream: cs, identity: identity}, nil
+}
+
+// authClientStream logs the authenticated peer of every message it receives.
+type authClientStream struct {
+	grpc.ClientStream
+	identity string
+}
+
+func (cs *authClientStream) RecvMsg(m interface{}) error {
+	if err := cs.ClientStream.RecvMsg(m); err != nil {
+		return err
+	}
+	log.Printf("%s receives %s from %s\n", *name, describe(m), cs.identity)
+
+	return nil
+}
+
+// describe names the kind of a received message for the logs.
+func describe(m interface{}) string {
+	switch msg := m.(type) {
+	case *pb.GameMessage:
+		return protocol.EventOf(msg).String()
+	case proto.Message:
+		return string(msg.ProtoReflect().Descriptor().Name())
+	default:
+		return fmt.Sprintf("%T", m)
+	}
+}
@@ -0,0 +1,366 @@
+package main
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Files of the CA in its directory, next to the issued certificates
+const (
+	caName   string = "ca"
+	caDBFile string = "ca.db.json"
+	crlFile  string = "ca.crl.pem"
+)
+
+// Default number of days a revocation list is valid. Players reject
+// certificates covered by an out of date list, so a CA must sign a new list
+// within this time, even if nothing was revoked.
+const crlDays int = 7
+
+// caRecord is a certificate issued by the CA.
+type caRecord struct {
+	Name      string     `json:"name"`
+	Serial    string     `json:"serial"`
+	NotAfter  time.Time  `json:"not_after"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+}
+
+// caDB is the database of the CA: what it has issued and revoked.
+type caDB struct {
+	CRLNumber int64      `json:"crl_number"`
+	Certs     []caRecord `json:"certs"`
+}
+
+// runCA runs the ca subcommand, which manages a local certificate authority:
+//
+//	dicegame ca init [-dir certs] [-days 3650] [-crl-days 7]
+//	dicegame ca issue -name carol [-dir certs] [-days 365]
+//	dicegame ca revoke -name carol [-dir certs] [-crl-days 7]
+//	dicegame ca crl [-dir certs] [-crl-days 7]
+func runCA(args []string) {
+	if len(args) == 0 {
+		log.Fatalf("Usage: dicegame ca init|issue|revoke|crl [flags]\n")
+	}
+
+	fs := flag.NewFlagSet("ca "+args[0], flag.ExitOnError)
+	dir := fs.String("dir", "certs", "Directory of the CA and the issued certificates")
+	days := fs.Int("days", 365, "Number of days the certificate is valid")
+	listDays := fs.Int("crl-days", crlDays, "Number of days the revocation list is valid")
+	player := fs.String("name", "", "Name of the player to issue a certificate for")
+
+	switch args[0] {
+	case "init":
+		fs.Parse(args[1:])
+		if err := initCA(*dir, *days, *listDays); err != nil {
+			log.Fatalf("Error: %s\n", err)
+		}
+		log.Printf("Created CA in %s\n", *dir)
+
+	case "issue":
+		fs.Parse(args[1:])
+		if *player == "" {
+			log.Fatalf("Error: -name is required\n")
+		}
+		if err := issueCert(*dir, *player, *days); err != nil {
+			log.Fatalf("Error: %s\n", err)
+		}
+		log.Printf("Issued certificate for %s in %s\n", *player, *dir)
+
+	case "revoke":
+		fs.Parse(args[1:])
+		if *player == "" {
+			log.Fatalf("Error: -name is required\n")
+		}
+		n, err := revokeCerts(*dir, *player, *listDays)
+		if err != nil {
+			log.Fatalf("Error: %s\n", err)
+		}
+		log.Printf("Revoked %d certificate(s) of %s, see %s\n", n, *player, filepath.Join(*dir, crlFile))
+
+	case "crl":
+		fs.Parse(args[1:])
+		db, err := loadDB(*dir)
+		if err != nil {
+			log.Fatalf("Error: %s\n", err)
+		}
+		if err := writeCRL(*dir, db, *listDays); err != nil {
+			log.Fatalf("Error: %s\n", err)
+		}
+		log.Printf("Signed a new revocation list in %s\n", filepath.Join(*dir, crlFile))
+
+	default:
+		log.Fatalf("Error: unknown ca command %q\n", args[0])
+	}
+}
+
+// initCA creates a self-signed CA certificate and key in dir, and an empty
+// revocation list valid for listDays.
+func initCA(dir string, days int, listDays int) error {
+	if _, err := os.Stat(filepath.Join(dir, caName+".cert.pem")); err == nil {
+		return fmt.Errorf("a CA already exists in %s", dir)
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return err
+	}
+
+	template, err := certTemplate("sec1-handin-02 dice game CA", days)
+	if err != nil {
+		return err
+	}
+	template.IsCA = true
+	template.BasicConstraintsValid = true
+	template.MaxPathLenZero = true
+	template.KeyUsage = x509.KeyUsageCertSign | x509.KeyUsageCRLSign
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	if err := writeKeyPair(dir, caName, der, key); err != nil {
+		return err
+	}
+
+	// Start with an empty revocation list
+	return writeCRL(dir, &caDB{Certs: []caRecord{}}, listDays)
+}
+
+// issueCert issues a certificate for player, signed by the CA in dir. It is
+// valid for both ends of a TLS connection, and names the player in its common
+// name and as a DNS name, next to localhost.
+func issueCert(dir string, player string, days int) error {
+	if err := checkPlayerName(player); err != nil {
+		return err
+	}
+
+	caCert, caKey, err := loadCA(dir)
+	if err != nil {
+		return err
+	}
+	db, err := loadDB(dir)
+	if err != nil {
+		return err
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return err
+	}
+
+	template, err := certTemplate(player, days)
+	if err != nil {
+		return err
+	}
+	template.DNSNames = []string{"localhost", player}
+	template.KeyUsage = x509.KeyUsageDigitalSignature
+	template.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth}
+	if template.NotAfter.After(caCert.NotAfter) {
+		template.NotAfter = caCert.NotAfter
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, caCert, &key.PublicKey, caKey)
+	if err != nil {
+		return err
+	}
+	if err := writeKeyPair(dir, player, der, key); err != nil {
+		return err
+	}
+
+	db.Certs = append(db.Certs, caRecord{
+		Name:     player,
+		Serial:   template.SerialNumber.Text(16),
+		NotAfter: template.NotAfter,
+	})
+	return db.save(dir)
+}
+
+// checkPlayerName returns an error if player cannot name a certificate: it
+// must be a single lowercase DNS label, since it is a DNS name of the
+// certificate and the name of its files.
+func checkPlayerName(player string) error {
+	if player == caName || player == "localhost" {
+		return fmt.Errorf("%q is reserved", player)
+	}
+	if len(player) == 0 || len(player) > 63 {
+		return fmt.Errorf("player name %q must be 1 to 63 characters long", player)
+	}
+	for i, c := range player {
+		switch {
+		case c >= 'a' && c <= 'z', c >= '0' && c <= '9':
+		case c == '-' && i > 0 && i < len(player)-1:
+		default:
+			return fmt.Errorf("player name %q may only hold a-z, 0-9 and inner hyphens", player)
+		}
+	}
+
+	return nil
+}
+
+// revokeCerts revokes every certificate the CA in dir issued to player, and
+// writes a new revocation list valid for listDays. It returns how many were
+// revoked.
+func revokeCerts(dir string, player string, listDays int) (int, error) {
+	db, err := loadDB(dir)
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	n := 0
+	for i, rec := range db.Certs {
+		if rec.Name == strings.ToLower(player) && rec.RevokedAt == nil {
+			db.Certs[i].RevokedAt = &now
+			n++
+		}
+	}
+	if n == 0 {
+		return 0, fmt.Errorf("no certificates of %s to revoke", player)
+	}
+
+	return n, writeCRL(dir, db, listDays)
+}
+
+// writeCRL signs a new revocation list of the certificates revoked in db,
+// and saves it along with db. It is valid for listDays, but not for longer
+// than the CA.
+func writeCRL(dir string, db *caDB, listDays int) error {
+	if listDays < 1 {
+		return errors.New("need at least 1 day of validity")
+	}
+	caCert, caKey, err := loadCA(dir)
+	if err != nil {
+		return err
+	}
+
+	revoked := []pkix.RevokedCertificate{}
+	for _, rec := range db.Certs {
+		if rec.RevokedAt == nil {
+			continue
+		}
+
+		serial, ok := new(big.Int).SetString(rec.Serial, 16)
+		if !ok {
+			return fmt.Errorf("bad serial %q in CA database", rec.Serial)
+		}
+		revoked = append(revoked, pkix.RevokedCertificate{SerialNumber: serial, RevocationTime: *rec.RevokedAt})
+	}
+
+	now := time.Now()
+	nextUpdate := now.AddDate(0, 0, listDays)
+	if nextUpdate.After(caCert.NotAfter) {
+		nextUpdate = caCert.NotAfter
+	}
+
+	db.CRLNumber++
+	der, err := x509.CreateRevocationList(rand.Reader, &x509.RevocationList{
+		RevokedCertificates: revoked,
+		Number:              big.NewInt(db.CRLNumber),
+		ThisUpdate:          now,
+		NextUpdate:          nextUpdate,
+	}, caCert, caKey)
+	if err != nil {
+		return err
+	}
+
+	crlPem := pem.EncodeToMemory(&pem.Block{Type: "X509 CRL", Bytes: der})
+	if err := os.WriteFile(filepath.Join(dir, crlFile), crlPem, 0o644); err != nil {
+		return err
+	}
+	return db.save(dir)
+}
+
+// loadCA loads the certificate and key of the CA in dir.
+func loadCA(dir string) (*x509.Certificate, crypto.Signer, error) {
+	ca, err := tls.LoadX509KeyPair(filepath.Join(dir, caName+".cert.pem"), filepath.Join(dir, caName+".key.pem"))
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot load CA: %w", err)
+	}
+	caCert, err := x509.ParseCertificate(ca.Certificate[0])
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return caCert, ca.PrivateKey.(crypto.Signer), nil
+}
+
+func loadDB(dir string) (*caDB, error) {
+	data, err := os.ReadFile(filepath.Join(dir, caDBFile))
+	if err != nil {
+		return nil, fmt.Errorf("cannot load CA database: %w", err)
+	}
+
+	db := &caDB{}
+	if err := json.Unmarshal(data, db); err != nil {
+		return nil, fmt.Errorf("cannot load CA database: %w", err)
+	}
+
+	return db, nil
+}
+
+func (db *caDB) save(dir string) error {
+	data, err := json.MarshalIndent(db, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(dir, caDBFile), append(data, '\n'), 0o644)
+}
+
+// certTemplate returns a template with a random serial number, valid from now
+// for the given number of days.
+func certTemplate(commonName string, days int) (*x509.Certificate, error) {
+	if days < 1 {
+		return nil, errors.New("need at least 1 day of validity")
+	}
+
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	return &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    now.Add(-time.Minute),
+		NotAfter:     now.AddDate(0, 0, days),
+	}, nil
+}
+
+// writeKeyPair writes <name>.cert.pem and <name>.key.pem to dir. The key is
+// only readable by its owner.
+func writeKeyPair(dir string, name string, der []byte, key *ecdsa.PrivateKey) error {
+	keyDer, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return err
+	}
+
+	certPem := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	if err := os.WriteFile(filepath.Join(dir, name+".cert.pem"), certPem, 0o644); err != nil {
+		return err
+	}
+
+	keyPem := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDer})
+	return os.WriteFile(filepath.Join(dir, name+".key.pem"), keyPem, 0o600)
+}
@@ -0,0 +1,22 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCheckPlayerName(t *testing.T) {
+	for _, player := range []string{"alice", "bob", "carol-2", "0", strings.Repeat("a", 63)} {
+		if err := checkPlayerName(player); err != nil {
+			t.Errorf("checkPlayerName(%q): %s", player, err)
+		}
+	}
+	for _, player := range []string{
+		"", "ca", "localhost", "Alice", "../alice", "alice/bob", "a.b", "a b", "a_b", "-a", "a-",
+		"alice\x00", "ålice", strings.Repeat("a", 64),
+	} {
+		if err := checkPlayerName(player); err == nil {
+			t.Errorf("checkPlayerName(%q) accepts the name", player)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+// Package commit defines commitment schemes that a player can commit to their
+// contribution with, and open it later. Every commitment is bound to a
+// context, such as a session and round, and only opens in that context.
+package commit
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/samsapti/sec1-handin-02/pedersen"
+	"github.com/samsapti/sec1-handin-02/zkp"
+)
+
+// Names of the schemes
+const (
+	NamePedersen   string = "pedersen"
+	NameHMACSHA256 string = "hmac-sha256"
+	NameSHA3       string = "sha3-256"
+)
+
+// ErrMismatch is returned by Verify when an opening does not match its
+// commitment.
+var ErrMismatch error = errors.New("opening does not match commitment")
+
+// Commitment is the encoding of a commitment, as sent over the wire.
+type Commitment []byte
+
+// Opening reveals the committed message and the randomness that hid it.
+type Opening struct {
+	Msg []byte
+	R   []byte
+}
+
+// Scheme is a commitment scheme. It must be hiding, so a commitment tells
+// nothing about the message, and binding, so it opens to no other message.
+type Scheme interface {
+	// Name identifies the scheme, e.g. in flags and during negotiation
+	Name() string
+
+	// Commit commits to msg in ctx with fresh randomness
+	Commit(msg []byte, ctx []byte) (Commitment, Opening, error)
+
+	// Verify checks that o opens c in ctx
+	Verify(c Commitment, o Opening, ctx []byte) error
+}
+
+// RangeProver is implemented by schemes that can prove, without opening a
+// commitment, that it commits to a big-endian integer in [0, n).
+type RangeProver interface {
+	ProveRange(c Commitment, o Opening, n uint64, ctx []byte) (*zkp.RangeProof, error)
+	VerifyRange(c Commitment, proof *zkp.RangeProof, n uint64, ctx []byte) error
+}
+
+// KnowledgeProver is implemented by schemes that can prove knowledge of the
+// opening of a commitment without revealing it.
+type KnowledgeProver interface {
+	ProveKnowledge(c Commitment, o Opening, ctx []byte) (*zkp.KnowledgeProof, error)
+	VerifyKnowledge(c Commitment, proof *zkp.KnowledgeProof, ctx []byte) error
+}
+
+// BatchVerifier is implemented by schemes that can verify many openings at
+// once, faster than one by one. VerifyBatch checks that os[i] opens cs[i] in
+// ctxs[i].
+type BatchVerifier interface {
+	VerifyBatch(cs []Commitment, os []Opening, ctxs [][]byte) error
+}
+
+// ByName returns the scheme with the given name. The parameters are only used
+// by Pedersen commitments.
+func ByName(name string, pp *pedersen.Params) (Scheme, error) {
+	switch name {
+	case NamePedersen:
+		return Pedersen(pp), nil
+	case NameHMACSHA256:
+		return HMACSHA256(), nil
+	case NameSHA3:
+		return SHA3(), nil
+	default:
+		return nil, fmt.Errorf("unknown commitment scheme %q", name)
+	}
+}
@@ -0,0 +1,86 @@
+package commit
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/samsapti/sec1-handin-02/pedersen"
+)
+
+func TestSchemes(t *testing.T) {
+	pp := pedersen.DeriveGenerators([]byte("test"), pedersen.Ristretto255())
+	for _, name := range []string{NamePedersen, NameHMACSHA256, NameSHA3} {
+		s, err := ByName(name, pp)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s.Name() != name {
+			t.Errorf("ByName(%q).Name() = %q", name, s.Name())
+		}
+
+		ctx := []byte("session 1 round 2 alice")
+		c, o, err := s.Commit([]byte{3}, ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := s.Verify(c, o, ctx); err != nil {
+			t.Errorf("%s: valid opening: %s", name, err)
+		}
+
+		if err := s.Verify(c, Opening{Msg: []byte{4}, R: o.R}, ctx); !errors.Is(err, ErrMismatch) {
+			t.Errorf("%s: other message: %v, want ErrMismatch", name, err)
+		}
+		if err := s.Verify(c, o, []byte("session 1 round 3 alice")); !errors.Is(err, ErrMismatch) {
+			t.Errorf("%s: other context: %v, want ErrMismatch", name, err)
+		}
+		r := append([]byte{}, o.R...)
+		r[0] ^= 1
+		if err := s.Verify(c, Opening{Msg: o.Msg, R: r}, ctx); !errors.Is(err, ErrMismatch) {
+			t.Errorf("%s: other randomness: %v, want ErrMismatch", name, err)
+		}
+	}
+
+	if _, err := ByName("md5", pp); err == nil {
+		t.Error("ByName accepts an unknown scheme")
+	}
+}
+
+func TestVerifyBatch(t *testing.T) {
+	s := Pedersen(pedersen.DeriveGenerators([]byte("test"), pedersen.Ristretto255()))
+	var cs []Commitment
+	var os []Opening
+	var ctxs [][]byte
+	for i := byte(0); i < 3; i++ {
+		ctx := []byte{'c', i}
+		c, o, err := s.Commit([]byte{i}, ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cs, os, ctxs = append(cs, c), append(os, o), append(ctxs, ctx)
+	}
+
+	if err := s.VerifyBatch(cs, os, ctxs); err != nil {
+		t.Errorf("valid batch: %s", err)
+	}
+
+	var be *pedersen.BatchError
+	if err := s.VerifyBatch(cs, os, [][]byte{ctxs[0], ctxs[2], ctxs[1]}); !errors.As(err, &be) || be.Index != 1 {
+		t.Errorf("swapped contexts: got %v, want &BatchError{Index: 1}", err)
+	}
+
+	// Mismatched lengths are an error, not a panic or a shorter batch
+	for _, tc := range []struct {
+		cs   []Commitment
+		os   []Opening
+		ctxs [][]byte
+	}{
+		{cs, os[:2], ctxs},
+		{cs, os, ctxs[:2]},
+		{cs[:2], os, ctxs},
+		{cs, nil, nil},
+	} {
+		if err := s.VerifyBatch(tc.cs, tc.os, tc.ctxs); err == nil || errors.As(err, &be) {
+			t.Errorf("batch of %d commitments, %d openings and %d contexts: got %v, want a length error", len(tc.cs), len(tc.os), len(tc.ctxs), err)
+		}
+	}
+}
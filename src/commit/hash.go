@@ -0,0 +1,82 @@
+package commit
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+
+	"golang.org/x/crypto/sha3"
+)
+
+// Length of the random key of a hash commitment
+const keyLen int = 32
+
+// Domain separation label for hash commitments
+const hashLabel string = "sec1-handin-02/commit/hash/"
+
+// HashScheme commits to a message by hashing it and the context under a
+// random 256-bit key, which is the randomness of the opening. It is binding
+// as long as the hash is collision resistant.
+type HashScheme struct {
+	name string
+	sum  func(key []byte, data []byte) []byte
+}
+
+// HMACSHA256 commits with HMAC-SHA256 keyed with the randomness.
+func HMACSHA256() *HashScheme {
+	return &HashScheme{
+		name: NameHMACSHA256,
+		sum: func(key []byte, data []byte) []byte {
+			mac := hmac.New(sha256.New, key)
+			mac.Write(data)
+			return mac.Sum(nil)
+		},
+	}
+}
+
+// SHA3 commits with SHA3-256 of the randomness followed by the data. SHA-3 is
+// not open to length extension, so it needs no HMAC construction.
+func SHA3() *HashScheme {
+	return &HashScheme{
+		name: NameSHA3,
+		sum: func(key []byte, data []byte) []byte {
+			h := sha3.New256()
+			h.Write(key)
+			h.Write(data)
+			return h.Sum(nil)
+		},
+	}
+}
+
+func (s *HashScheme) Name() string {
+	return s.name
+}
+
+func (s *HashScheme) Commit(msg []byte, ctx []byte) (Commitment, Opening, error) {
+	key := make([]byte, keyLen)
+	if _, err := rand.Read(key); err != nil {
+		return nil, Opening{}, err
+	}
+
+	return s.sum(key, hashInput(msg, ctx)), Opening{Msg: msg, R: key}, nil
+}
+
+func (s *HashScheme) Verify(c Commitment, o Opening, ctx []byte) error {
+	if len(o.R) != keyLen || !hmac.Equal(c, s.sum(o.R, hashInput(o.Msg, ctx))) {
+		return ErrMismatch
+	}
+
+	return nil
+}
+
+// hashInput encodes the context and message unambiguously.
+func hashInput(msg []byte, ctx []byte) []byte {
+	data := append([]byte(hashLabel), lengthPrefixed(ctx)...)
+	return append(data, lengthPrefixed(msg)...)
+}
+
+// lengthPrefixed prefixes b with its length.
+func lengthPrefixed(b []byte) []byte {
+	return append(binary.BigEndian.AppendUint32(nil, uint32(len(b))), b...)
+}
@@ -0,0 +1,106 @@
+package commit
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/samsapti/sec1-handin-02/pedersen"
+	"github.com/samsapti/sec1-handin-02/zkp"
+)
+
+// PedersenScheme commits to a message m, read as a big-endian integer, with
+// g^m * h^r bound to the context. It is hiding even against an unbounded
+// adversary.
+type PedersenScheme struct {
+	Params *pedersen.Params
+}
+
+func Pedersen(pp *pedersen.Params) *PedersenScheme {
+	return &PedersenScheme{Params: pp}
+}
+
+func (s *PedersenScheme) Name() string {
+	return NamePedersen
+}
+
+func (s *PedersenScheme) Commit(msg []byte, ctx []byte) (Commitment, Opening, error) {
+	r := s.Params.GetR()
+	c := s.Params.Bind(s.Params.GetCommitment(new(big.Int).SetBytes(msg), r), ctx)
+
+	return s.Params.Group.Encode(c), Opening{Msg: msg, R: r.Bytes()}, nil
+}
+
+func (s *PedersenScheme) Verify(c Commitment, o Opening, ctx []byte) error {
+	elem, err := s.Params.Group.Decode(c)
+	if err != nil {
+		return err
+	}
+	if !s.Params.ValidateBoundCommitment(elem, ctx, new(big.Int).SetBytes(o.Msg), new(big.Int).SetBytes(o.R)) {
+		return ErrMismatch
+	}
+
+	return nil
+}
+
+// ProveRange proves that c commits to a value in [0, n), after removing the
+// context it is bound to.
+func (s *PedersenScheme) ProveRange(c Commitment, o Opening, n uint64, ctx []byte) (*zkp.RangeProof, error) {
+	elem, err := s.Params.Group.Decode(c)
+	if err != nil {
+		return nil, err
+	}
+	opening := pedersen.Opening{M: new(big.Int).SetBytes(o.Msg), R: new(big.Int).SetBytes(o.R)}
+
+	return zkp.ProveRange(s.Params, s.Params.Unbind(elem, ctx), opening, n, ctx)
+}
+
+func (s *PedersenScheme) VerifyRange(c Commitment, proof *zkp.RangeProof, n uint64, ctx []byte) error {
+	elem, err := s.Params.Group.Decode(c)
+	if err != nil {
+		return err
+	}
+
+	return proof.Verify(s.Params, s.Params.Unbind(elem, ctx), n, ctx)
+}
+
+// ProveKnowledge proves that we know the opening of c, after removing the
+// context it is bound to.
+func (s *PedersenScheme) ProveKnowledge(c Commitment, o Opening, ctx []byte) (*zkp.KnowledgeProof, error) {
+	elem, err := s.Params.Group.Decode(c)
+	if err != nil {
+		return nil, err
+	}
+	opening := pedersen.Opening{M: new(big.Int).SetBytes(o.Msg), R: new(big.Int).SetBytes(o.R)}
+
+	return zkp.ProveKnowledge(s.Params, s.Params.Unbind(elem, ctx), opening, ctx), nil
+}
+
+func (s *PedersenScheme) VerifyKnowledge(c Commitment, proof *zkp.KnowledgeProof, ctx []byte) error {
+	elem, err := s.Params.Group.Decode(c)
+	if err != nil {
+		return err
+	}
+
+	return proof.Verify(s.Params, s.Params.Unbind(elem, ctx), ctx)
+}
+
+// VerifyBatch removes the contexts from the commitments, and validates them
+// together with pedersen.BatchValidate.
+func (s *PedersenScheme) VerifyBatch(cs []Commitment, os []Opening, ctxs [][]byte) error {
+	if len(os) != len(cs) || len(ctxs) != len(cs) {
+		return fmt.Errorf("batch of %d commitments with %d openings and %d contexts", len(cs), len(os), len(ctxs))
+	}
+
+	elems := make([]pedersen.Element, len(cs))
+	openings := make([]pedersen.Opening, len(os))
+	for i := range cs {
+		elem, err := s.Params.Group.Decode(cs[i])
+		if err != nil {
+			return &pedersen.BatchError{Index: i}
+		}
+		elems[i] = s.Params.Unbind(elem, ctxs[i])
+		openings[i] = pedersen.Opening{M: new(big.Int).SetBytes(os[i].Msg), R: new(big.Int).SetBytes(os[i].R)}
+	}
+
+	return s.Params.BatchValidate(elems, openings)
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"context"
+	"log"
+	"time"
+
+	pb "github.com/samsapti/sec1-handin-02/grpc"
+	"github.com/samsapti/sec1-handin-02/protocol"
+)
+
+// stream is what both ends of a Play stream have in common.
+type stream interface {
+	Send(*pb.GameMessage) error
+	Recv() (*pb.GameMessage, error)
+}
+
+// conn is a Play stream to a peer, and how to let go of it.
+type conn struct {
+	stream
+	end  func() // Ends the stream once the game is over
+	drop func() // Abandons a broken stream
+}
+
+// dial opens a Play stream to player.
+func (g *game) dial(player string) (*conn, error) {
+	ctx, cancel := context.WithCancel(g.ctx)
+	st, err := g.clients[player].Play(ctx)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	if err := checkPeer(st.Context(), player); err != nil {
+		cancel()
+		g.quit.quit(exitProtocol, true, "cannot open stream to %s: %s", player, err)
+	}
+
+	return &conn{stream: st, end: func() { st.CloseSend() }, drop: cancel}, nil
+}
+
+// accepted wraps a Play stream opened by a peer.
+func accepted(ps peerStream) *conn {
+	release := func() { close(ps.done) }
+	return &conn{stream: ps.stream, end: release, drop: release}
+}
+
+type received struct {
+	msg *pb.GameMessage
+	err error
+}
+
+// recv returns the next message from player. If the stream breaks, it
+// reconnects and waits again. A player that takes longer than the deadline
+// for the step forfeits the round. Time spent reconnecting does not count,
+// but only up to -resume-timeout per step, so a peer cannot stall forever by
+// breaking its stream over and over.
+func (g *game) recv(player string, state protocol.State) *pb.GameMessage {
+	end := time.Now().Add(g.timeouts[state.Phase])
+	grace := *resumeTimeout
+	deadline := time.NewTimer(time.Until(end))
+	defer deadline.Stop()
+
+	for {
+		c := g.conns[player]
+		recv := make(chan received, 1)
+		go func() {
+			msg, err := c.Recv()
+			recv <- received{msg, err}
+		}()
+
+		var start time.Time
+		select {
+		case in := <-recv:
+			if in.err == nil {
+				return in.msg
+			}
+			start = time.Now()
+			g.reconnect(player, in.err)
+
+		case ps := <-g.srv.streamsOf(player):
+			// The peer lost its stream before we noticed
+			start = time.Now()
+			c.drop()
+			g.conns[player] = accepted(ps)
+			if err := g.resend(player); err != nil {
+				g.reconnect(player, err)
+			}
+
+		case <-deadline.C:
+			g.forfeit(player, state)
+		}
+
+		// Extend the deadline by the time spent reconnecting, while there is
+		// grace left
+		spent := time.Since(start)
+		if spent > grace {
+			spent = grace
+		}
+		grace -= spent
+		end = end.Add(spent)
+
+		if !deadline.Stop() {
+			<-deadline.C
+		}
+		deadline.Reset(time.Until(end))
+	}
+}
+
+// reconnect replaces the broken stream to player, and re-sends everything we
+// sent them. Our commitments are thus sent again identically, and the peer
+// ignores what it already got. We open the new stream if we opened the first
+// one, and otherwise wait for the peer to.
+func (g *game) reconnect(player string, cause error) {
+	log.Printf("%s lost the stream to %s: %s\n", *name, player, cause)
+	g.conns[player].drop()
+
+	giveUp := time.After(*resumeTimeout)
+	wait := 100 * time.Millisecond
+	for {
+		var c *conn
+		if player > canonical(*name) {
+			var err error
+			if c, err = g.dial(player); err != nil {
+				cause = err
+			}
+		} else {
+			select {
+			case ps := <-g.srv.streamsOf(player):
+				c = accepted(ps)
+			case <-giveUp:
+				g.giveUp(player, cause)
+			}
+		}
+
+		if c != nil {
+			g.conns[player] = c
+			err := g.resend(player)
+			if err == nil {
+				log.Printf("%s resumes the session with %s, re-sending %d message(s)\n", *name, player, len(g.outbox[player]))
+				return
+			}
+			c.drop()
+			cause = err
+		}
+
+		select {
+		case <-time.After(wait):
+			if wait *= 2; wait > 2*time.Second {
+				wait = 2 * time.Second
+			}
+		case <-giveUp:
+			g.giveUp(player, cause)
+		}
+	}
+}
+
+func (g *game) giveUp(player string, cause error) {
+	g.quit.quit(exitNetwork, true, "could not resume the session with %s within %s: %s", player, *resumeTimeout, cause)
+}
+
+// resend sends everything we sent to player again, in order.
+func (g *game) resend(player string) error {
+	for _, msg := range g.outbox[player] {
+		if err := g.conns[player].Send(msg); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,228 @@
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// credStore holds the key pair and trust store in use, and reloads them when
+// their files change. New connections pick up what was reloaded, while
+// established ones carry on.
+type credStore struct {
+	certFile   string
+	keyFile    string
+	trustFiles string
+	crlFiles   string
+
+	// Guarded by mu
+	mu       sync.RWMutex
+	cert     *tls.Certificate
+	trust    *trustStore
+	modTimes map[string]time.Time
+	loadedAt time.Time
+	reloads  int
+	lastErr  error
+}
+
+// newCredStore loads our own key pair, from certs/<name>.cert.pem unless
+// given, and the -trust and -crl files.
+func newCredStore() (*credStore, error) {
+	c := &credStore{certFile: *certFile, keyFile: *keyFile, trustFiles: *trust, crlFiles: *crl}
+	if c.certFile == "" {
+		c.certFile = filepath.Join("certs", strings.ToLower(*name)+".cert.pem")
+	}
+	if c.keyFile == "" {
+		c.keyFile = strings.TrimSuffix(c.certFile, ".cert.pem") + ".key.pem"
+	}
+
+	if err := c.load(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// load reads all files, and only replaces what is in use if they are valid.
+func (c *credStore) load() error {
+	modTimes := c.stat()
+
+	cert, err := tls.LoadX509KeyPair(c.certFile, c.keyFile)
+	if err != nil {
+		err = fmt.Errorf("cannot load key pair: %w", err)
+	}
+	var t *trustStore
+	if err == nil {
+		t, err = loadTrust(c.trustFiles, c.crlFiles)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.modTimes = modTimes
+	c.lastErr = err
+	if err != nil {
+		return err
+	}
+
+	if c.cert != nil {
+		c.reloads++
+	}
+	c.cert = &cert
+	c.trust = t
+	c.loadedAt = time.Now()
+
+	return nil
+}
+
+// stat returns the modification times of all files, so that changes, and
+// files added to or removed from a glob, can be detected.
+func (c *credStore) stat() map[string]time.Time {
+	modTimes := map[string]time.Time{}
+	files := []string{c.certFile, c.keyFile}
+	for _, patterns := range []string{c.trustFiles, c.crlFiles} {
+		if patterns == "" {
+			continue
+		}
+		for _, pattern := range strings.Split(patterns, ",") {
+			matches, _ := filepath.Glob(pattern)
+			files = append(files, matches...)
+		}
+	}
+
+	for _, f := range files {
+		if info, err := os.Stat(f); err == nil {
+			modTimes[f] = info.ModTime()
+		} else {
+			modTimes[f] = time.Time{}
+		}
+	}
+
+	return modTimes
+}
+
+func (c *credStore) changed() bool {
+	modTimes := c.stat()
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if len(modTimes) != len(c.modTimes) {
+		return true
+	}
+	for f, t := range modTimes {
+		if old, ok := c.modTimes[f]; !ok || !old.Equal(t) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// watch polls the files every interval, and reloads them when they change.
+func (c *credStore) watch(interval time.Duration) {
+	for range time.Tick(interval) {
+		if !c.changed() {
+			continue
+		}
+
+		if err := c.load(); err != nil {
+			log.Printf("%s keeps the old certificates, reload failed: %s\n", *name, err)
+			continue
+		}
+
+		leaf, _ := x509.ParseCertificate(c.certificate().Certificate[0])
+		log.Printf("%s reloaded certificates: serial %x valid until %s\n", *name, leaf.SerialNumber, leaf.NotAfter.Format(time.RFC3339))
+	}
+}
+
+func (c *credStore) certificate() *tls.Certificate {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.cert
+}
+
+func (c *credStore) trustStore() *trustStore {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.trust
+}
+
+// serverConfig and clientConfig return TLS configurations that always use
+// the current key pair and trust store. We verify peers ourselves in
+// VerifyConnection, to check revocation and give clear diagnostics. The
+// standard verification is therefore turned off on the client side, and
+// only asks for a certificate on the server side.
+func (c *credStore) serverConfig() *tls.Config {
+	return &tls.Config{
+		GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			return c.certificate(), nil
+		},
+		ClientAuth: tls.RequireAnyClientCert,
+		VerifyConnection: func(cs tls.ConnectionState) error {
+			return c.trustStore().verify(cs, x509.ExtKeyUsageClientAuth, "")
+		},
+	}
+}
+
+func (c *credStore) clientConfig() *tls.Config {
+	return &tls.Config{
+		GetClientCertificate: func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			return c.certificate(), nil
+		},
+		InsecureSkipVerify: true,
+		VerifyConnection: func(cs tls.ConnectionState) error {
+			return c.trustStore().verify(cs, x509.ExtKeyUsageServerAuth, cs.ServerName)
+		},
+	}
+}
+
+// credStatus is what the status endpoint reports about the certificates.
+type credStatus struct {
+	Subject   string    `json:"subject"`
+	Serial    string    `json:"serial"`
+	NotAfter  time.Time `json:"not_after"`
+	LoadedAt  time.Time `json:"loaded_at"`
+	Reloads   int       `json:"reloads"`
+	CRLs      int       `json:"crls"`
+	LastError string    `json:"last_error,omitempty"`
+}
+
+func (c *credStore) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	c.mu.RLock()
+	leaf, err := x509.ParseCertificate(c.cert.Certificate[0])
+	st := credStatus{LoadedAt: c.loadedAt, Reloads: c.reloads, CRLs: len(c.trust.crls)}
+	if c.lastErr != nil {
+		st.LastError = c.lastErr.Error()
+	}
+	c.mu.RUnlock()
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	st.Subject = leaf.Subject.CommonName
+	st.Serial = leaf.SerialNumber.Text(16)
+	st.NotAfter = leaf.NotAfter
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(st)
+}
+
+// serveStatus serves the status of the certificates at /status.
+func serveStatus(addr string, c *credStore) {
+	mux := http.NewServeMux()
+	mux.Handle("/status", c)
+
+	log.Printf("%s serves status at http://%s/status\n", *name, addr)
+	if err := http.ListenAndServe(addr, mux); err != nil {
+		log.Fatalf("%s failed to serve status: %s\n", *name, err)
+	}
+}
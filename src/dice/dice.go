@@ -0,0 +1,52 @@
+// Package dice implements a fair n-sided die thrown jointly by several
+// players. Each player contributes a uniformly random value in [0, n), and
+// the sum of the contributions mod n is uniform as long as one of them is.
+package dice
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+)
+
+// MaxSides is the most sides a die may have. Sides are sent as 32-bit
+// integers, and a range proof grows with the number of sides.
+const MaxSides uint64 = 1000
+
+// Check returns an error if n is not a usable number of sides.
+func Check(n uint64) error {
+	if n < 2 {
+		return fmt.Errorf("a die needs at least 2 sides, not %d", n)
+	}
+	if n > MaxSides {
+		return fmt.Errorf("a die may have at most %d sides, not %d", MaxSides, n)
+	}
+
+	return nil
+}
+
+// Roll returns a uniformly random contribution in [0, n).
+func Roll(n uint64) (uint64, error) {
+	x, err := rand.Int(rand.Reader, new(big.Int).SetUint64(n))
+	if err != nil {
+		return 0, err
+	}
+
+	return x.Uint64(), nil
+}
+
+// Valid reports whether x is a contribution to an n-sided die.
+func Valid(n uint64, x uint64) bool {
+	return x < n
+}
+
+// Combine returns the face of an n-sided die, in [1, n], given all the
+// players' contributions.
+func Combine(n uint64, contributions ...uint64) uint64 {
+	var sum uint64
+	for _, x := range contributions {
+		sum = (sum + x%n) % n
+	}
+
+	return sum + 1
+}
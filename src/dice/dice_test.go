@@ -0,0 +1,79 @@
+package dice
+
+import (
+	"math"
+	"testing"
+)
+
+// For every fixed contribution a, the combination with b over all of [0, n)
+// hits each face exactly once. So the result is uniform as long as one
+// player's contribution is uniform, whatever the others choose.
+func TestCombineUniform(t *testing.T) {
+	for _, n := range []uint64{4, 6, 20, 100} {
+		for a := uint64(0); a < n; a++ {
+			hits := make([]int, n+1)
+			for b := uint64(0); b < n; b++ {
+				face := Combine(n, a, b)
+				if face < 1 || face > n {
+					t.Fatalf("Combine(%d, %d, %d) = %d, not in [1, %d]", n, a, b, face, n)
+				}
+				hits[face]++
+			}
+			for face := uint64(1); face <= n; face++ {
+				if hits[face] != 1 {
+					t.Errorf("n = %d, a = %d: face %d hit %d times", n, a, face, hits[face])
+				}
+			}
+		}
+	}
+}
+
+func TestCombineOrder(t *testing.T) {
+	if Combine(6, 1, 2, 5) != Combine(6, 5, 1, 2) {
+		t.Error("Combine depends on the order of the contributions")
+	}
+}
+
+func TestRoll(t *testing.T) {
+	for i := 0; i < 1000; i++ {
+		x, err := Roll(6)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !Valid(6, x) {
+			t.Fatalf("Roll(6) = %d", x)
+		}
+	}
+}
+
+func TestValid(t *testing.T) {
+	tests := []struct {
+		n, x uint64
+		want bool
+	}{
+		{6, 0, true},
+		{6, 5, true},
+		{6, 6, false},
+		{6, 42, false},
+		{100, 99, true},
+		{100, 100, false},
+	}
+	for _, tt := range tests {
+		if got := Valid(tt.n, tt.x); got != tt.want {
+			t.Errorf("Valid(%d, %d) = %t, want %t", tt.n, tt.x, got, tt.want)
+		}
+	}
+}
+
+func TestCheck(t *testing.T) {
+	for _, n := range []uint64{0, 1, MaxSides + 1, 1 << 32, 1<<32 + 6, math.MaxUint64} {
+		if err := Check(n); err == nil {
+			t.Errorf("Check(%d) accepts a die with %d sides", n, n)
+		}
+	}
+	for _, n := range []uint64{2, 4, 6, 20, 100, MaxSides} {
+		if err := Check(n); err != nil {
+			t.Errorf("Check(%d): %s", n, err)
+		}
+	}
+}
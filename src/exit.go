@@ -0,0 +1,145 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	pb "github.com/samsapti/sec1-handin-02/grpc"
+)
+
+// Exit codes, so that scripts can tell why a player stopped. Bad flags and
+// other setup errors exit with 1.
+const (
+	exitCheating    int = 2 // Someone was caught cheating
+	exitPeerAborted int = 3 // A peer aborted the game
+	exitNetwork     int = 4 // A peer could not be reached
+	exitProtocol    int = 5 // A peer broke the protocol, or disagreed in negotiation
+	exitInterrupted int = 6 // We got SIGINT or SIGTERM
+	exitTimeout     int = 7 // A peer did not take a step in time, and forfeits
+)
+
+// How long to wait for each peer to take our abort, and for the gRPC server to
+// stop gracefully
+const (
+	abortTimeout     time.Duration = time.Second
+	gracefulShutdown time.Duration = 2 * time.Second
+)
+
+// quitter stops a player early: it tells the peers why, cancels everything
+// running under its context, waits for the gRPC server to stop, and exits.
+type quitter struct {
+	ctx     context.Context
+	cancel  context.CancelFunc
+	stopped chan struct{} // Closed once the gRPC server has stopped
+
+	// Guarded by mu
+	mu       sync.Mutex
+	quitting bool
+	clients  map[string]pb.DiceGameClient
+	session  []byte
+	round    uint32
+}
+
+func newQuitter(ctx context.Context) *quitter {
+	q := &quitter{stopped: make(chan struct{}), clients: map[string]pb.DiceGameClient{}}
+	q.ctx, q.cancel = context.WithCancel(ctx)
+	return q
+}
+
+// addPeer records a player to tell when we quit.
+func (q *quitter) addPeer(player string, client pb.DiceGameClient) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.clients[player] = client
+}
+
+// hasPeer reports whether player was recorded with addPeer.
+func (q *quitter) hasPeer(player string) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	_, ok := q.clients[player]
+	return ok
+}
+
+func (q *quitter) setSession(session []byte) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.session = session
+}
+
+func (q *quitter) setRound(round uint32) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.round = round
+}
+
+// claim reports whether we are the first to quit.
+func (q *quitter) claim() bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.quitting {
+		return false
+	}
+	q.quitting = true
+	return true
+}
+
+// quit stops the player, telling the peers why if notify is set, and exits
+// with code. Only the first call does so; later ones block until the process
+// exits.
+func (q *quitter) quit(code int, notify bool, format string, args ...interface{}) {
+	if !q.claim() {
+		select {}
+	}
+	q.stop(code, notify, fmt.Sprintf(format, args...))
+}
+
+// stop does the work of quit, once claimed.
+func (q *quitter) stop(code int, notify bool, reason string) {
+	log.Printf("%s leaves the game: %s\n", *name, reason)
+
+	q.mu.Lock()
+	abort := &pb.Abort{Reason: reason, SessionId: q.session, Round: q.round}
+	clients := map[string]pb.DiceGameClient{}
+	for p, client := range q.clients {
+		clients[p] = client
+	}
+	q.mu.Unlock()
+
+	if notify {
+		var wg sync.WaitGroup
+		for p, client := range clients {
+			wg.Add(1)
+			go func(p string, client pb.DiceGameClient) {
+				defer wg.Done()
+
+				ctx, cancel := context.WithTimeout(context.Background(), abortTimeout)
+				defer cancel()
+				if _, err := client.Abort(ctx, abort); err != nil {
+					log.Printf("%s could not tell %s: %s\n", *name, p, err)
+				}
+			}(p, client)
+		}
+		wg.Wait()
+	}
+
+	q.stopServer()
+	os.Exit(code)
+}
+
+// stopServer cancels everything running under the context of q, and waits
+// for the gRPC server to stop gracefully.
+func (q *quitter) stopServer() {
+	q.cancel()
+	select {
+	case <-q.stopped:
+	case <-time.After(gracefulShutdown):
+		log.Printf("%s stops without waiting for the server\n", *name)
+	}
+}
@@ -0,0 +1,489 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"math/big"
+	"sort"
+	"time"
+
+	"github.com/samsapti/sec1-handin-02/commit"
+	"github.com/samsapti/sec1-handin-02/dice"
+	pb "github.com/samsapti/sec1-handin-02/grpc"
+	"github.com/samsapti/sec1-handin-02/protocol"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+)
+
+// game holds what a player needs to play the rounds of a session.
+type game struct {
+	conns    map[string]*conn
+	clients  map[string]pb.DiceGameClient
+	srv      *server
+	ctx      context.Context // For opening Play streams
+	players  []string        // Names of the peers, sorted
+	quit     *quitter
+	timeouts map[protocol.Phase]time.Duration // How long a peer may take for each step
+	scheme   commit.Scheme
+	session  []byte
+	sides    uint64
+	tr       *transcript
+
+	// What we sent to and received from each peer, to resume after a
+	// reconnect
+	outbox map[string][]*pb.GameMessage
+	seen   map[string]map[seenKey]*pb.GameMessage
+}
+
+// seenKey identifies a message from a peer by its round and step.
+type seenKey struct {
+	round uint32
+	event protocol.Event
+}
+
+// newGame connects to every peer over a Play stream. We open the streams to
+// peers whose names sort after ours, and wait for the others to open theirs.
+func newGame(clients map[string]pb.DiceGameClient, srv *server, scheme commit.Scheme, session []byte, sides uint64, tr *transcript) *game {
+	g := &game{
+		conns:   map[string]*conn{},
+		clients: clients,
+		srv:     srv,
+		ctx:     metadata.AppendToOutgoingContext(srv.quit.ctx, playerKey, *name, sessionKey, hex.EncodeToString(session)),
+		quit:    srv.quit,
+		timeouts: map[protocol.Phase]time.Duration{
+			protocol.AwaitCommit:  *commitTimeout,
+			protocol.AwaitThrow:   *throwTimeout,
+			protocol.AwaitOpen:    *openTimeout,
+			protocol.AwaitAck:     *ackTimeout,
+			protocol.AwaitVerdict: *ackTimeout,
+		},
+		scheme:  scheme,
+		session: session,
+		sides:   sides,
+		tr:      tr,
+		outbox:  map[string][]*pb.GameMessage{},
+		seen:    map[string]map[seenKey]*pb.GameMessage{},
+	}
+
+	for p := range clients {
+		g.players = append(g.players, p)
+		g.seen[p] = map[seenKey]*pb.GameMessage{}
+	}
+	sort.Strings(g.players)
+
+	timeout := time.After(*joinTimeout)
+	for _, p := range g.players {
+		if p > canonical(*name) {
+			c, err := g.dial(p)
+			if err != nil {
+				g.quit.quit(exitNetwork, true, "cannot open stream to %s: %s", p, err)
+			}
+			g.conns[p] = c
+			continue
+		}
+
+		select {
+		case ps := <-srv.streamsOf(p):
+			g.conns[p] = accepted(ps)
+		case <-timeout:
+			g.quit.quit(exitNetwork, true, "%s did not open a stream within %s", p, *joinTimeout)
+		case <-g.quit.ctx.Done():
+			select {}
+		}
+	}
+
+	return g
+}
+
+// close ends the streams to all peers.
+func (g *game) close() {
+	for _, c := range g.conns {
+		c.end()
+	}
+	g.tr.close()
+}
+
+// send records msg and sends it to player.
+func (g *game) send(player string, msg *pb.GameMessage) {
+	g.tr.message(*name, msg)
+	g.deliver(player, msg)
+}
+
+// broadcast records msg once and sends it to every peer.
+func (g *game) broadcast(msg *pb.GameMessage) {
+	g.tr.message(*name, msg)
+	for _, p := range g.players {
+		g.deliver(p, msg)
+	}
+}
+
+// deliver sends msg to player, and keeps it to re-send after a reconnect. The
+// peer is at most one round behind us, so older messages are dropped.
+func (g *game) deliver(player string, msg *pb.GameMessage) {
+	_, round := protocol.MessageContext(msg)
+	outbox := []*pb.GameMessage{}
+	for _, old := range g.outbox[player] {
+		if _, r := protocol.MessageContext(old); r+1 >= round {
+			outbox = append(outbox, old)
+		}
+	}
+	g.outbox[player] = append(outbox, msg)
+	if err := g.conns[player].Send(msg); err != nil {
+		g.reconnect(player, err)
+	}
+}
+
+// abort leaves the game because a peer broke the protocol.
+func (g *game) abort(format string, args ...interface{}) {
+	g.quit.quit(exitProtocol, true, format, args...)
+}
+
+// forfeit records that player forfeits the round by not taking a step in
+// time, and ends the game.
+func (g *game) forfeit(player string, state protocol.State) {
+	log.Printf("%s records round %d as forfeited by %s\n", *name, state.Round, player)
+	g.tr.forfeit(state.Round, player, fmt.Sprintf("still %s after %s", state.Phase, g.timeouts[state.Phase]))
+	g.quit.quit(exitTimeout, true, "%s forfeits round %d: still %s after %s", player, state.Round, state.Phase, g.timeouts[state.Phase])
+}
+
+// step reads the next message from player and feeds it to the player's state
+// machine. It returns the message and what we must send in response. A peer
+// that breaks the protocol, or does not answer in time, ends the game.
+func (g *game) step(player string, state *protocol.State) (*pb.GameMessage, []protocol.Action) {
+	msg := g.recv(player, *state)
+	for g.repeated(player, msg) {
+		msg = g.recv(player, *state)
+	}
+
+	next, actions, err := protocol.Step(*state, msg)
+	*state = next
+	if err != nil {
+		g.abort("%s: %s", player, err)
+	}
+	g.tr.message(player, msg)
+
+	return msg, actions
+}
+
+// repeated reports whether msg was received before, as happens when a peer
+// re-sends after a reconnect. A message that differs from the first one for
+// its round and step would re-roll the round, and ends the game.
+func (g *game) repeated(player string, msg *pb.GameMessage) bool {
+	_, round := protocol.MessageContext(msg)
+	key := seenKey{round: round, event: protocol.EventOf(msg)}
+
+	first, ok := g.seen[player][key]
+	if !ok {
+		g.seen[player][key] = msg
+		return false
+	}
+	if !proto.Equal(first, msg) {
+		g.abort("%s re-sent a different %s in round %d", player, key.event, round)
+	}
+	log.Printf("%s ignores repeated %s from %s\n", *name, key.event, player)
+
+	return true
+}
+
+// roll returns our contribution to the die.
+func (g *game) roll() uint64 {
+	m, err := dice.Roll(g.sides)
+	if err != nil {
+		log.Fatalf("Error: %s\n", err)
+	}
+
+	return m
+}
+
+// commit rolls our contribution to the die and commits to it, bound to the
+// session, round and our name.
+func (g *game) commit(round uint32) (uint64, commit.Opening, *pb.Commitment) {
+	m := g.roll()
+	ctx := roundContext(g.session, round, *name)
+	c, o, err := g.scheme.Commit(new(big.Int).SetUint64(m).Bytes(), ctx)
+	if err != nil {
+		log.Fatalf("Error: %s\n", err)
+	}
+
+	commitment := &pb.Commitment{C: c, SessionId: g.session, Round: round}
+	if err := prove(g.scheme, commitment, o, g.sides, ctx); err != nil {
+		log.Fatalf("Error: %s\n", err)
+	}
+
+	return m, o, commitment
+}
+
+// checkCommitment verifies the proofs that go with a player's commitment,
+// before we answer it. A player whose proofs fail is cheating.
+func (g *game) checkCommitment(round uint32, player string, commitment *pb.Commitment) {
+	record := func(proof string, err error) {
+		g.tr.proofCheck(round, player, proof, err)
+	}
+	if err := checkProofs(g.scheme, commitment, g.sides, roundContext(g.session, round, player), record); err != nil {
+		g.quit.quit(exitCheating, true, "%s is cheating: %s", player, err)
+	}
+}
+
+func (g *game) opening(round uint32, o commit.Opening) *pb.Opening {
+	return &pb.Opening{
+		M:         o.Msg,
+		R:         o.R,
+		SessionId: g.session,
+		Round:     round,
+	}
+}
+
+// validate checks that opening opens the player's commitment to a valid
+// contribution, records the result, and returns the contribution. If matched,
+// the opening is already known to match the commitment.
+func (g *game) validate(round uint32, player string, commitment *pb.Commitment, opening *pb.Opening, matched bool) (uint64, error) {
+	err := checkContribution(g.sides, opening.M)
+	if err == nil && !matched {
+		err = g.scheme.Verify(commitment.C, commit.Opening{Msg: opening.M, R: opening.R}, roundContext(g.session, round, player))
+	}
+	g.tr.validation(round, player, err)
+	if err != nil {
+		return 0, err
+	}
+
+	return new(big.Int).SetBytes(opening.M).Uint64(), nil
+}
+
+// matchAll reports whether every player's opening matches their commitment,
+// if the scheme can check them all at once. Otherwise, or if one does not
+// match, they must be validated one by one.
+func (g *game) matchAll(round uint32, commitments map[string]*pb.Commitment, openings map[string]*pb.Opening) bool {
+	bv, ok := g.scheme.(commit.BatchVerifier)
+	if !ok {
+		return false
+	}
+
+	cs, ops, ctxs := []commit.Commitment{}, []commit.Opening{}, [][]byte{}
+	for _, p := range g.players {
+		cs = append(cs, commitments[p].C)
+		ops = append(ops, commit.Opening{Msg: openings[p].M, R: openings[p].R})
+		ctxs = append(ctxs, roundContext(g.session, round, p))
+	}
+
+	return bv.VerifyBatch(cs, ops, ctxs) == nil
+}
+
+// checkOpening checks that (m, r) opens the commitment c of player to a
+// contribution in [0, sides).
+func checkOpening(scheme commit.Scheme, sides uint64, session []byte, round uint32, player string, c []byte, m []byte, r []byte) error {
+	if err := checkContribution(sides, m); err != nil {
+		return err
+	}
+
+	return scheme.Verify(c, commit.Opening{Msg: m, R: r}, roundContext(session, round, player))
+}
+
+// checkContribution checks that m is a contribution in [0, sides).
+func checkContribution(sides uint64, m []byte) error {
+	v := new(big.Int).SetBytes(m)
+	if !v.IsUint64() || !dice.Valid(sides, v.Uint64()) {
+		return fmt.Errorf("contribution %d is not in [0, %d)", v, sides)
+	}
+
+	return nil
+}
+
+// playClassic plays a two-player round where the starting player commits, the
+// other player throws in the clear, and the starting player then opens.
+func (g *game) playClassic(round uint32) uint64 {
+	peer := g.players[0]
+	role := protocol.Responder
+	if startsRound(round, peer) {
+		role = protocol.Committer
+		log.Printf("%s starts round %d\n", *name, round)
+	}
+
+	// Only the committer needs a commitment and its proofs; the responder
+	// throws in the clear
+	var m uint64
+	var o commit.Opening
+	var commitment *pb.Commitment
+	if role == protocol.Committer {
+		m, o, commitment = g.commit(round)
+	} else {
+		m = g.roll()
+	}
+	var peerCommitment *pb.Commitment
+	var peerM uint64
+	var validationErr error
+
+	state, actions := protocol.Begin(role, g.session, round)
+	for {
+		for _, action := range actions {
+			switch action {
+			case protocol.SendCommitment:
+				log.Printf("%s sends commitment: %x\n", *name, commitment.C)
+				g.send(peer, &pb.GameMessage{Msg: &pb.GameMessage_Commitment{Commitment: commitment}})
+			case protocol.SendThrow:
+				g.send(peer, &pb.GameMessage{Msg: &pb.GameMessage_Throw{
+					Throw: &pb.DieThrow{Val: m, SessionId: g.session, Round: round},
+				}})
+				log.Printf("%s sends their die throw: %d\n", *name, m)
+			case protocol.SendOpening:
+				log.Printf("%s sends opening: (m: %d, r: %x)\n", *name, m, o.R)
+				g.send(peer, &pb.GameMessage{Msg: &pb.GameMessage_Opening{Opening: g.opening(round, o)}})
+			case protocol.SendAck:
+				g.send(peer, &pb.GameMessage{Msg: &pb.GameMessage_Ack{
+					Ack: &pb.Acknowledgement{Ack: validationErr == nil, SessionId: g.session, Round: round},
+				}})
+				if validationErr != nil {
+					// The acknowledgement already tells the peer why we leave
+					g.quit.quit(exitCheating, false, "%s is cheating: %s", peer, validationErr)
+				}
+				log.Printf("%s confirms commitment is valid\n", *name)
+			default:
+				g.abort("unknown action %d in round %d", action, round)
+			}
+		}
+		if state.Phase == protocol.Done {
+			break
+		}
+
+		var msg *pb.GameMessage
+		msg, actions = g.step(peer, &state)
+		switch in := msg.Msg.(type) {
+		case *pb.GameMessage_Commitment:
+			peerCommitment = in.Commitment
+			log.Printf("%s receives commitment: %x\n", *name, peerCommitment.C)
+			g.checkCommitment(round, peer, peerCommitment)
+
+		case *pb.GameMessage_Throw:
+			peerM = in.Throw.Val
+			log.Printf("%s receives peer's die throw: %d\n", *name, peerM)
+			if !dice.Valid(g.sides, peerM) {
+				g.abort("die throw %d is not in [0, %d)", peerM, g.sides)
+			}
+
+		case *pb.GameMessage_Opening:
+			opening := in.Opening
+			log.Printf("%s receives opening: (m: %d, r: %x)\n", *name, new(big.Int).SetBytes(opening.M), opening.R)
+
+			// Validate commitment from peer; the acknowledgement is sent by
+			// the action that comes with the opening
+			peerM, validationErr = g.validate(round, peer, peerCommitment, opening, false)
+
+		case *pb.GameMessage_Ack:
+			log.Printf("%s receives acknowledgement: %t\n", *name, in.Ack.Ack)
+			if !in.Ack.Ack {
+				g.quit.quit(exitCheating, false, "%s got caught cheating, run!", *name)
+			}
+		}
+	}
+
+	return dice.Combine(g.sides, m, peerM)
+}
+
+// playSymmetric plays a round where all players commit, then all open, and
+// then all tell each other whose openings failed to validate. The result is
+// the sum of all contributions.
+func (g *game) playSymmetric(round uint32) uint64 {
+	log.Printf("%s plays round %d\n", *name, round)
+
+	states := map[string]*protocol.State{}
+	for _, p := range g.players {
+		state, _ := protocol.Begin(protocol.Symmetric, g.session, round)
+		states[p] = &state
+	}
+
+	// Exchange commitments
+	m, o, ownCommitment := g.commit(round)
+	log.Printf("%s sends commitment: %x\n", *name, ownCommitment.C)
+	g.broadcast(&pb.GameMessage{Msg: &pb.GameMessage_Commitment{Commitment: ownCommitment}})
+
+	commitments := map[string]*pb.Commitment{}
+	for _, p := range g.players {
+		msg, _ := g.step(p, states[p])
+		commitments[p] = msg.GetCommitment()
+		log.Printf("%s receives commitment from %s: %x\n", *name, p, commitments[p].C)
+		g.checkCommitment(round, p, commitments[p])
+	}
+
+	// Exchange openings, now that all players are committed
+	log.Printf("%s sends opening: (m: %d, r: %x)\n", *name, m, o.R)
+	ownOpening := g.opening(round, o)
+	g.broadcast(&pb.GameMessage{Msg: &pb.GameMessage_Opening{Opening: ownOpening}})
+
+	openings := map[string]*pb.Opening{}
+	for _, p := range g.players {
+		msg, _ := g.step(p, states[p])
+		openings[p] = msg.GetOpening()
+	}
+
+	// Validate every peer's opening, all at once if we can
+	matched := g.matchAll(round, commitments, openings)
+	cheaters := []string{}
+	values := []uint64{m}
+	for _, p := range g.players {
+		log.Printf("%s receives opening from %s: (m: %d, r: %x)\n", *name, p, new(big.Int).SetBytes(openings[p].M), openings[p].R)
+
+		v, err := g.validate(round, p, commitments[p], openings[p], matched)
+		if err != nil {
+			log.Printf("%s catches %s cheating: %s\n", *name, p, err)
+			cheaters = append(cheaters, p)
+			continue
+		}
+		values = append(values, v)
+	}
+
+	// Tell everyone who we caught. The digests also reveal whether anyone
+	// sent different commitments or openings to different players.
+	commitments[canonical(*name)] = ownCommitment
+	openings[canonical(*name)] = ownOpening
+	ownVerdict := &pb.Verdict{
+		Cheaters:  cheaters,
+		Digest:    digest(commitments, openings),
+		SessionId: g.session,
+		Round:     round,
+	}
+	g.broadcast(&pb.GameMessage{Msg: &pb.GameMessage_Verdict{Verdict: ownVerdict}})
+
+	caught := len(cheaters) > 0
+	for _, p := range g.players {
+		msg, _ := g.step(p, states[p])
+		verdict := msg.GetVerdict()
+		for _, c := range verdict.Cheaters {
+			log.Printf("%s reports that %s is cheating\n", p, c)
+			caught = true
+		}
+		if !bytes.Equal(verdict.Digest, ownVerdict.Digest) {
+			log.Printf("%s saw different commitments or openings than %s\n", p, *name)
+			caught = true
+		}
+	}
+	if caught {
+		// Every player has seen the verdicts, so all of them leave
+		g.quit.quit(exitCheating, false, "someone is cheating")
+	}
+	log.Printf("%s confirms all commitments are valid\n", *name)
+
+	return dice.Combine(g.sides, values...)
+}
+
+// digest hashes every player's commitment and opening, in order of name.
+func digest(commitments map[string]*pb.Commitment, openings map[string]*pb.Opening) []byte {
+	players := []string{}
+	for p := range commitments {
+		players = append(players, p)
+	}
+	sort.Strings(players)
+
+	hash := sha256.New()
+	for _, p := range players {
+		for _, field := range [][]byte{[]byte(p), commitments[p].C, openings[p].M, openings[p].R} {
+			binary.Write(hash, binary.BigEndian, uint32(len(field)))
+			hash.Write(field)
+		}
+	}
+
+	return hash.Sum(nil)
+}
@@ -20,31 +20,536 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+type Greeting struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Player string `protobuf:"bytes,1,opt,name=player,proto3" json:"player,omitempty"`
+}
+
+func (x *Greeting) Reset() {
+	*x = Greeting{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_grpc_main_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Greeting) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Greeting) ProtoMessage() {}
+
+func (x *Greeting) ProtoReflect() protoreflect.Message {
+	mi := &file_grpc_main_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Greeting.ProtoReflect.Descriptor instead.
+func (*Greeting) Descriptor() ([]byte, []int) {
+	return file_grpc_main_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Greeting) GetPlayer() string {
+	if x != nil {
+		return x.Player
+	}
+	return ""
+}
+
+// Settings both players must agree on before the first round
+type Negotiation struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Version uint32  `protobuf:"varint,1,opt,name=version,proto3" json:"version,omitempty"`
+	Params  *Params `protobuf:"bytes,2,opt,name=params,proto3" json:"params,omitempty"`
+	Rounds  uint32  `protobuf:"varint,3,opt,name=rounds,proto3" json:"rounds,omitempty"`
+	Sides   uint32  `protobuf:"varint,4,opt,name=sides,proto3" json:"sides,omitempty"`
+	Nonce   []byte  `protobuf:"bytes,5,opt,name=nonce,proto3" json:"nonce,omitempty"`
+	Player  string  `protobuf:"bytes,6,opt,name=player,proto3" json:"player,omitempty"`
+	Mode    string  `protobuf:"bytes,7,opt,name=mode,proto3" json:"mode,omitempty"`
+	Players uint32  `protobuf:"varint,8,opt,name=players,proto3" json:"players,omitempty"`
+	Scheme  string  `protobuf:"bytes,9,opt,name=scheme,proto3" json:"scheme,omitempty"` // Commitment scheme
+}
+
+func (x *Negotiation) Reset() {
+	*x = Negotiation{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_grpc_main_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Negotiation) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Negotiation) ProtoMessage() {}
+
+func (x *Negotiation) ProtoReflect() protoreflect.Message {
+	mi := &file_grpc_main_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Negotiation.ProtoReflect.Descriptor instead.
+func (*Negotiation) Descriptor() ([]byte, []int) {
+	return file_grpc_main_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Negotiation) GetVersion() uint32 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *Negotiation) GetParams() *Params {
+	if x != nil {
+		return x.Params
+	}
+	return nil
+}
+
+func (x *Negotiation) GetRounds() uint32 {
+	if x != nil {
+		return x.Rounds
+	}
+	return 0
+}
+
+func (x *Negotiation) GetSides() uint32 {
+	if x != nil {
+		return x.Sides
+	}
+	return 0
+}
+
+func (x *Negotiation) GetNonce() []byte {
+	if x != nil {
+		return x.Nonce
+	}
+	return nil
+}
+
+func (x *Negotiation) GetPlayer() string {
+	if x != nil {
+		return x.Player
+	}
+	return ""
+}
+
+func (x *Negotiation) GetMode() string {
+	if x != nil {
+		return x.Mode
+	}
+	return ""
+}
+
+func (x *Negotiation) GetPlayers() uint32 {
+	if x != nil {
+		return x.Players
+	}
+	return 0
+}
+
+func (x *Negotiation) GetScheme() string {
+	if x != nil {
+		return x.Scheme
+	}
+	return ""
+}
+
+// Public commitment parameters. The generators g and h must be derivable from
+// the seed, so that neither player can know log_g(h).
+type Params struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Group string `protobuf:"bytes,1,opt,name=group,proto3" json:"group,omitempty"`
+	Seed  []byte `protobuf:"bytes,2,opt,name=seed,proto3" json:"seed,omitempty"`
+	G     []byte `protobuf:"bytes,3,opt,name=g,proto3" json:"g,omitempty"`
+	H     []byte `protobuf:"bytes,4,opt,name=h,proto3" json:"h,omitempty"`
+}
+
+func (x *Params) Reset() {
+	*x = Params{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_grpc_main_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Params) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Params) ProtoMessage() {}
+
+func (x *Params) ProtoReflect() protoreflect.Message {
+	mi := &file_grpc_main_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Params.ProtoReflect.Descriptor instead.
+func (*Params) Descriptor() ([]byte, []int) {
+	return file_grpc_main_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Params) GetGroup() string {
+	if x != nil {
+		return x.Group
+	}
+	return ""
+}
+
+func (x *Params) GetSeed() []byte {
+	if x != nil {
+		return x.Seed
+	}
+	return nil
+}
+
+func (x *Params) GetG() []byte {
+	if x != nil {
+		return x.G
+	}
+	return nil
+}
+
+func (x *Params) GetH() []byte {
+	if x != nil {
+		return x.H
+	}
+	return nil
+}
+
+// A message on a Play stream
+type GameMessage struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Types that are assignable to Msg:
+	//	*GameMessage_Commitment
+	//	*GameMessage_Throw
+	//	*GameMessage_Opening
+	//	*GameMessage_Ack
+	//	*GameMessage_Verdict
+	Msg isGameMessage_Msg `protobuf_oneof:"msg"`
+}
+
+func (x *GameMessage) Reset() {
+	*x = GameMessage{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_grpc_main_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GameMessage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GameMessage) ProtoMessage() {}
+
+func (x *GameMessage) ProtoReflect() protoreflect.Message {
+	mi := &file_grpc_main_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GameMessage.ProtoReflect.Descriptor instead.
+func (*GameMessage) Descriptor() ([]byte, []int) {
+	return file_grpc_main_proto_rawDescGZIP(), []int{3}
+}
+
+func (m *GameMessage) GetMsg() isGameMessage_Msg {
+	if m != nil {
+		return m.Msg
+	}
+	return nil
+}
+
+func (x *GameMessage) GetCommitment() *Commitment {
+	if x, ok := x.GetMsg().(*GameMessage_Commitment); ok {
+		return x.Commitment
+	}
+	return nil
+}
+
+func (x *GameMessage) GetThrow() *DieThrow {
+	if x, ok := x.GetMsg().(*GameMessage_Throw); ok {
+		return x.Throw
+	}
+	return nil
+}
+
+func (x *GameMessage) GetOpening() *Opening {
+	if x, ok := x.GetMsg().(*GameMessage_Opening); ok {
+		return x.Opening
+	}
+	return nil
+}
+
+func (x *GameMessage) GetAck() *Acknowledgement {
+	if x, ok := x.GetMsg().(*GameMessage_Ack); ok {
+		return x.Ack
+	}
+	return nil
+}
+
+func (x *GameMessage) GetVerdict() *Verdict {
+	if x, ok := x.GetMsg().(*GameMessage_Verdict); ok {
+		return x.Verdict
+	}
+	return nil
+}
+
+type isGameMessage_Msg interface {
+	isGameMessage_Msg()
+}
+
+type GameMessage_Commitment struct {
+	Commitment *Commitment `protobuf:"bytes,1,opt,name=commitment,proto3,oneof"`
+}
+
+type GameMessage_Throw struct {
+	Throw *DieThrow `protobuf:"bytes,2,opt,name=throw,proto3,oneof"`
+}
+
+type GameMessage_Opening struct {
+	Opening *Opening `protobuf:"bytes,3,opt,name=opening,proto3,oneof"`
+}
+
+type GameMessage_Ack struct {
+	Ack *Acknowledgement `protobuf:"bytes,4,opt,name=ack,proto3,oneof"`
+}
+
+type GameMessage_Verdict struct {
+	Verdict *Verdict `protobuf:"bytes,5,opt,name=verdict,proto3,oneof"`
+}
+
+func (*GameMessage_Commitment) isGameMessage_Msg() {}
+
+func (*GameMessage_Throw) isGameMessage_Msg() {}
+
+func (*GameMessage_Opening) isGameMessage_Msg() {}
+
+func (*GameMessage_Ack) isGameMessage_Msg() {}
+
+func (*GameMessage_Verdict) isGameMessage_Msg() {}
+
+// Group elements use the canonical encoding of the group, and scalars are
+// encoded as unsigned big-endian byte strings. Every game message carries the
+// session ID agreed on during negotiation and the round it belongs to.
 type Commitment struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	C uint64 `protobuf:"varint,1,opt,name=c,proto3" json:"c,omitempty"`
+	C          []byte      `protobuf:"bytes,1,opt,name=c,proto3" json:"c,omitempty"`
+	SessionId  []byte      `protobuf:"bytes,2,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	Round      uint32      `protobuf:"varint,3,opt,name=round,proto3" json:"round,omitempty"`
+	RangeProof *RangeProof `protobuf:"bytes,4,opt,name=range_proof,json=rangeProof,proto3" json:"range_proof,omitempty"` // With Pedersen commitments
+	Proof      *Proof      `protobuf:"bytes,5,opt,name=proof,proto3" json:"proof,omitempty"`                             // Of knowledge of the opening, with Pedersen commitments
+}
+
+func (x *Commitment) Reset() {
+	*x = Commitment{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_grpc_main_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Commitment) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Commitment) ProtoMessage() {}
+
+func (x *Commitment) ProtoReflect() protoreflect.Message {
+	mi := &file_grpc_main_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Commitment.ProtoReflect.Descriptor instead.
+func (*Commitment) Descriptor() ([]byte, []int) {
+	return file_grpc_main_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *Commitment) GetC() []byte {
+	if x != nil {
+		return x.C
+	}
+	return nil
+}
+
+func (x *Commitment) GetSessionId() []byte {
+	if x != nil {
+		return x.SessionId
+	}
+	return nil
+}
+
+func (x *Commitment) GetRound() uint32 {
+	if x != nil {
+		return x.Round
+	}
+	return 0
+}
+
+func (x *Commitment) GetRangeProof() *RangeProof {
+	if x != nil {
+		return x.RangeProof
+	}
+	return nil
+}
+
+func (x *Commitment) GetProof() *Proof {
+	if x != nil {
+		return x.Proof
+	}
+	return nil
+}
+
+// Shows that the sender knows the opening (m, r) of a commitment, with a
+// challenge e and responses for m and r
+type Proof struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	E  []byte `protobuf:"bytes,1,opt,name=e,proto3" json:"e,omitempty"`
+	Zm []byte `protobuf:"bytes,2,opt,name=zm,proto3" json:"zm,omitempty"`
+	Zr []byte `protobuf:"bytes,3,opt,name=zr,proto3" json:"zr,omitempty"`
+}
+
+func (x *Proof) Reset() {
+	*x = Proof{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_grpc_main_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Proof) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Proof) ProtoMessage() {}
+
+func (x *Proof) ProtoReflect() protoreflect.Message {
+	mi := &file_grpc_main_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Proof.ProtoReflect.Descriptor instead.
+func (*Proof) Descriptor() ([]byte, []int) {
+	return file_grpc_main_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *Proof) GetE() []byte {
+	if x != nil {
+		return x.E
+	}
+	return nil
+}
+
+func (x *Proof) GetZm() []byte {
+	if x != nil {
+		return x.Zm
+	}
+	return nil
+}
+
+func (x *Proof) GetZr() []byte {
+	if x != nil {
+		return x.Zr
+	}
+	return nil
+}
+
+// Shows that a commitment opens to a contribution in [0, n), with a challenge
+// and a response for each possible contribution
+type RangeProof struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	E [][]byte `protobuf:"bytes,1,rep,name=e,proto3" json:"e,omitempty"`
+	Z [][]byte `protobuf:"bytes,2,rep,name=z,proto3" json:"z,omitempty"`
 }
 
-func (x *Commitment) Reset() {
-	*x = Commitment{}
+func (x *RangeProof) Reset() {
+	*x = RangeProof{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_grpc_main_proto_msgTypes[0]
+		mi := &file_grpc_main_proto_msgTypes[6]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *Commitment) String() string {
+func (x *RangeProof) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Commitment) ProtoMessage() {}
+func (*RangeProof) ProtoMessage() {}
 
-func (x *Commitment) ProtoReflect() protoreflect.Message {
-	mi := &file_grpc_main_proto_msgTypes[0]
+func (x *RangeProof) ProtoReflect() protoreflect.Message {
+	mi := &file_grpc_main_proto_msgTypes[6]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -55,16 +560,23 @@ func (x *Commitment) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use Commitment.ProtoReflect.Descriptor instead.
-func (*Commitment) Descriptor() ([]byte, []int) {
-	return file_grpc_main_proto_rawDescGZIP(), []int{0}
+// Deprecated: Use RangeProof.ProtoReflect.Descriptor instead.
+func (*RangeProof) Descriptor() ([]byte, []int) {
+	return file_grpc_main_proto_rawDescGZIP(), []int{6}
 }
 
-func (x *Commitment) GetC() uint64 {
+func (x *RangeProof) GetE() [][]byte {
 	if x != nil {
-		return x.C
+		return x.E
 	}
-	return 0
+	return nil
+}
+
+func (x *RangeProof) GetZ() [][]byte {
+	if x != nil {
+		return x.Z
+	}
+	return nil
 }
 
 type Opening struct {
@@ -72,14 +584,16 @@ type Opening struct {
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	M uint64 `protobuf:"varint,1,opt,name=m,proto3" json:"m,omitempty"`
-	R uint64 `protobuf:"varint,2,opt,name=r,proto3" json:"r,omitempty"`
+	M         []byte `protobuf:"bytes,1,opt,name=m,proto3" json:"m,omitempty"`
+	R         []byte `protobuf:"bytes,2,opt,name=r,proto3" json:"r,omitempty"`
+	SessionId []byte `protobuf:"bytes,3,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	Round     uint32 `protobuf:"varint,4,opt,name=round,proto3" json:"round,omitempty"`
 }
 
 func (x *Opening) Reset() {
 	*x = Opening{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_grpc_main_proto_msgTypes[1]
+		mi := &file_grpc_main_proto_msgTypes[7]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -92,7 +606,7 @@ func (x *Opening) String() string {
 func (*Opening) ProtoMessage() {}
 
 func (x *Opening) ProtoReflect() protoreflect.Message {
-	mi := &file_grpc_main_proto_msgTypes[1]
+	mi := &file_grpc_main_proto_msgTypes[7]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -105,20 +619,34 @@ func (x *Opening) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Opening.ProtoReflect.Descriptor instead.
 func (*Opening) Descriptor() ([]byte, []int) {
-	return file_grpc_main_proto_rawDescGZIP(), []int{1}
+	return file_grpc_main_proto_rawDescGZIP(), []int{7}
 }
 
-func (x *Opening) GetM() uint64 {
+func (x *Opening) GetM() []byte {
 	if x != nil {
 		return x.M
 	}
-	return 0
+	return nil
 }
 
-func (x *Opening) GetR() uint64 {
+func (x *Opening) GetR() []byte {
 	if x != nil {
 		return x.R
 	}
+	return nil
+}
+
+func (x *Opening) GetSessionId() []byte {
+	if x != nil {
+		return x.SessionId
+	}
+	return nil
+}
+
+func (x *Opening) GetRound() uint32 {
+	if x != nil {
+		return x.Round
+	}
 	return 0
 }
 
@@ -127,13 +655,15 @@ type DieThrow struct {
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Val uint64 `protobuf:"varint,1,opt,name=val,proto3" json:"val,omitempty"`
+	Val       uint64 `protobuf:"varint,1,opt,name=val,proto3" json:"val,omitempty"`
+	SessionId []byte `protobuf:"bytes,2,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	Round     uint32 `protobuf:"varint,3,opt,name=round,proto3" json:"round,omitempty"`
 }
 
 func (x *DieThrow) Reset() {
 	*x = DieThrow{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_grpc_main_proto_msgTypes[2]
+		mi := &file_grpc_main_proto_msgTypes[8]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -146,7 +676,7 @@ func (x *DieThrow) String() string {
 func (*DieThrow) ProtoMessage() {}
 
 func (x *DieThrow) ProtoReflect() protoreflect.Message {
-	mi := &file_grpc_main_proto_msgTypes[2]
+	mi := &file_grpc_main_proto_msgTypes[8]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -159,7 +689,7 @@ func (x *DieThrow) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use DieThrow.ProtoReflect.Descriptor instead.
 func (*DieThrow) Descriptor() ([]byte, []int) {
-	return file_grpc_main_proto_rawDescGZIP(), []int{2}
+	return file_grpc_main_proto_rawDescGZIP(), []int{8}
 }
 
 func (x *DieThrow) GetVal() uint64 {
@@ -169,18 +699,34 @@ func (x *DieThrow) GetVal() uint64 {
 	return 0
 }
 
+func (x *DieThrow) GetSessionId() []byte {
+	if x != nil {
+		return x.SessionId
+	}
+	return nil
+}
+
+func (x *DieThrow) GetRound() uint32 {
+	if x != nil {
+		return x.Round
+	}
+	return 0
+}
+
 type Acknowledgement struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Ack bool `protobuf:"varint,1,opt,name=ack,proto3" json:"ack,omitempty"`
+	Ack       bool   `protobuf:"varint,1,opt,name=ack,proto3" json:"ack,omitempty"`
+	SessionId []byte `protobuf:"bytes,2,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	Round     uint32 `protobuf:"varint,3,opt,name=round,proto3" json:"round,omitempty"`
 }
 
 func (x *Acknowledgement) Reset() {
 	*x = Acknowledgement{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_grpc_main_proto_msgTypes[3]
+		mi := &file_grpc_main_proto_msgTypes[9]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -193,7 +739,7 @@ func (x *Acknowledgement) String() string {
 func (*Acknowledgement) ProtoMessage() {}
 
 func (x *Acknowledgement) ProtoReflect() protoreflect.Message {
-	mi := &file_grpc_main_proto_msgTypes[3]
+	mi := &file_grpc_main_proto_msgTypes[9]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -206,7 +752,7 @@ func (x *Acknowledgement) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Acknowledgement.ProtoReflect.Descriptor instead.
 func (*Acknowledgement) Descriptor() ([]byte, []int) {
-	return file_grpc_main_proto_rawDescGZIP(), []int{3}
+	return file_grpc_main_proto_rawDescGZIP(), []int{9}
 }
 
 func (x *Acknowledgement) GetAck() bool {
@@ -216,28 +762,257 @@ func (x *Acknowledgement) GetAck() bool {
 	return false
 }
 
+func (x *Acknowledgement) GetSessionId() []byte {
+	if x != nil {
+		return x.SessionId
+	}
+	return nil
+}
+
+func (x *Acknowledgement) GetRound() uint32 {
+	if x != nil {
+		return x.Round
+	}
+	return 0
+}
+
+// A player's conclusion of a round: the players whose openings did not match
+// their commitments, and a digest of all commitments and openings it saw
+type Verdict struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Cheaters  []string `protobuf:"bytes,1,rep,name=cheaters,proto3" json:"cheaters,omitempty"`
+	Digest    []byte   `protobuf:"bytes,2,opt,name=digest,proto3" json:"digest,omitempty"`
+	SessionId []byte   `protobuf:"bytes,3,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	Round     uint32   `protobuf:"varint,4,opt,name=round,proto3" json:"round,omitempty"`
+}
+
+func (x *Verdict) Reset() {
+	*x = Verdict{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_grpc_main_proto_msgTypes[10]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Verdict) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Verdict) ProtoMessage() {}
+
+func (x *Verdict) ProtoReflect() protoreflect.Message {
+	mi := &file_grpc_main_proto_msgTypes[10]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Verdict.ProtoReflect.Descriptor instead.
+func (*Verdict) Descriptor() ([]byte, []int) {
+	return file_grpc_main_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *Verdict) GetCheaters() []string {
+	if x != nil {
+		return x.Cheaters
+	}
+	return nil
+}
+
+func (x *Verdict) GetDigest() []byte {
+	if x != nil {
+		return x.Digest
+	}
+	return nil
+}
+
+func (x *Verdict) GetSessionId() []byte {
+	if x != nil {
+		return x.SessionId
+	}
+	return nil
+}
+
+func (x *Verdict) GetRound() uint32 {
+	if x != nil {
+		return x.Round
+	}
+	return 0
+}
+
+// Sent before a player leaves the game early. Round is 0 before the first
+// round.
+type Abort struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Reason    string `protobuf:"bytes,1,opt,name=reason,proto3" json:"reason,omitempty"`
+	SessionId []byte `protobuf:"bytes,2,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	Round     uint32 `protobuf:"varint,3,opt,name=round,proto3" json:"round,omitempty"`
+}
+
+func (x *Abort) Reset() {
+	*x = Abort{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_grpc_main_proto_msgTypes[11]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Abort) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Abort) ProtoMessage() {}
+
+func (x *Abort) ProtoReflect() protoreflect.Message {
+	mi := &file_grpc_main_proto_msgTypes[11]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Abort.ProtoReflect.Descriptor instead.
+func (*Abort) Descriptor() ([]byte, []int) {
+	return file_grpc_main_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *Abort) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *Abort) GetSessionId() []byte {
+	if x != nil {
+		return x.SessionId
+	}
+	return nil
+}
+
+func (x *Abort) GetRound() uint32 {
+	if x != nil {
+		return x.Round
+	}
+	return 0
+}
+
 var File_grpc_main_proto protoreflect.FileDescriptor
 
 var file_grpc_main_proto_rawDesc = []byte{
 	0x0a, 0x0f, 0x67, 0x72, 0x70, 0x63, 0x2f, 0x6d, 0x61, 0x69, 0x6e, 0x2e, 0x70, 0x72, 0x6f, 0x74,
-	0x6f, 0x22, 0x1a, 0x0a, 0x0a, 0x43, 0x6f, 0x6d, 0x6d, 0x69, 0x74, 0x6d, 0x65, 0x6e, 0x74, 0x12,
-	0x0c, 0x0a, 0x01, 0x63, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x01, 0x63, 0x22, 0x25, 0x0a,
-	0x07, 0x4f, 0x70, 0x65, 0x6e, 0x69, 0x6e, 0x67, 0x12, 0x0c, 0x0a, 0x01, 0x6d, 0x18, 0x01, 0x20,
-	0x01, 0x28, 0x04, 0x52, 0x01, 0x6d, 0x12, 0x0c, 0x0a, 0x01, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28,
-	0x04, 0x52, 0x01, 0x72, 0x22, 0x1c, 0x0a, 0x08, 0x44, 0x69, 0x65, 0x54, 0x68, 0x72, 0x6f, 0x77,
-	0x12, 0x10, 0x0a, 0x03, 0x76, 0x61, 0x6c, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x03, 0x76,
-	0x61, 0x6c, 0x22, 0x23, 0x0a, 0x0f, 0x41, 0x63, 0x6b, 0x6e, 0x6f, 0x77, 0x6c, 0x65, 0x64, 0x67,
-	0x65, 0x6d, 0x65, 0x6e, 0x74, 0x12, 0x10, 0x0a, 0x03, 0x61, 0x63, 0x6b, 0x18, 0x01, 0x20, 0x01,
-	0x28, 0x08, 0x52, 0x03, 0x61, 0x63, 0x6b, 0x32, 0x63, 0x0a, 0x08, 0x44, 0x69, 0x63, 0x65, 0x47,
-	0x61, 0x6d, 0x65, 0x12, 0x2a, 0x0a, 0x0e, 0x53, 0x65, 0x6e, 0x64, 0x43, 0x6f, 0x6d, 0x6d, 0x69,
-	0x74, 0x6d, 0x65, 0x6e, 0x74, 0x12, 0x0b, 0x2e, 0x43, 0x6f, 0x6d, 0x6d, 0x69, 0x74, 0x6d, 0x65,
-	0x6e, 0x74, 0x1a, 0x09, 0x2e, 0x44, 0x69, 0x65, 0x54, 0x68, 0x72, 0x6f, 0x77, 0x22, 0x00, 0x12,
-	0x2b, 0x0a, 0x0b, 0x53, 0x65, 0x6e, 0x64, 0x4f, 0x70, 0x65, 0x6e, 0x69, 0x6e, 0x67, 0x12, 0x08,
-	0x2e, 0x4f, 0x70, 0x65, 0x6e, 0x69, 0x6e, 0x67, 0x1a, 0x10, 0x2e, 0x41, 0x63, 0x6b, 0x6e, 0x6f,
-	0x77, 0x6c, 0x65, 0x64, 0x67, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x22, 0x00, 0x42, 0x29, 0x5a, 0x27,
-	0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x73, 0x61, 0x6d, 0x73, 0x61,
-	0x70, 0x74, 0x69, 0x2f, 0x73, 0x65, 0x63, 0x31, 0x2d, 0x68, 0x61, 0x6e, 0x64, 0x69, 0x6e, 0x2d,
-	0x30, 0x32, 0x2f, 0x67, 0x72, 0x70, 0x63, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x6f, 0x22, 0x22, 0x0a, 0x08, 0x47, 0x72, 0x65, 0x65, 0x74, 0x69, 0x6e, 0x67, 0x12, 0x16, 0x0a,
+	0x06, 0x70, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x70,
+	0x6c, 0x61, 0x79, 0x65, 0x72, 0x22, 0xea, 0x01, 0x0a, 0x0b, 0x4e, 0x65, 0x67, 0x6f, 0x74, 0x69,
+	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x18, 0x0a, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12,
+	0x1f, 0x0a, 0x06, 0x70, 0x61, 0x72, 0x61, 0x6d, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x07, 0x2e, 0x50, 0x61, 0x72, 0x61, 0x6d, 0x73, 0x52, 0x06, 0x70, 0x61, 0x72, 0x61, 0x6d, 0x73,
+	0x12, 0x16, 0x0a, 0x06, 0x72, 0x6f, 0x75, 0x6e, 0x64, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0d,
+	0x52, 0x06, 0x72, 0x6f, 0x75, 0x6e, 0x64, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x73, 0x69, 0x64, 0x65,
+	0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x05, 0x73, 0x69, 0x64, 0x65, 0x73, 0x12, 0x14,
+	0x0a, 0x05, 0x6e, 0x6f, 0x6e, 0x63, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x05, 0x6e,
+	0x6f, 0x6e, 0x63, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x70, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x18, 0x06,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x70, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x12, 0x12, 0x0a, 0x04,
+	0x6d, 0x6f, 0x64, 0x65, 0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6d, 0x6f, 0x64, 0x65,
+	0x12, 0x18, 0x0a, 0x07, 0x70, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x73, 0x18, 0x08, 0x20, 0x01, 0x28,
+	0x0d, 0x52, 0x07, 0x70, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x73, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x63,
+	0x68, 0x65, 0x6d, 0x65, 0x18, 0x09, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x73, 0x63, 0x68, 0x65,
+	0x6d, 0x65, 0x22, 0x4e, 0x0a, 0x06, 0x50, 0x61, 0x72, 0x61, 0x6d, 0x73, 0x12, 0x14, 0x0a, 0x05,
+	0x67, 0x72, 0x6f, 0x75, 0x70, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x67, 0x72, 0x6f,
+	0x75, 0x70, 0x12, 0x12, 0x0a, 0x04, 0x73, 0x65, 0x65, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c,
+	0x52, 0x04, 0x73, 0x65, 0x65, 0x64, 0x12, 0x0c, 0x0a, 0x01, 0x67, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x0c, 0x52, 0x01, 0x67, 0x12, 0x0c, 0x0a, 0x01, 0x68, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0c, 0x52,
+	0x01, 0x68, 0x22, 0xde, 0x01, 0x0a, 0x0b, 0x47, 0x61, 0x6d, 0x65, 0x4d, 0x65, 0x73, 0x73, 0x61,
+	0x67, 0x65, 0x12, 0x2d, 0x0a, 0x0a, 0x63, 0x6f, 0x6d, 0x6d, 0x69, 0x74, 0x6d, 0x65, 0x6e, 0x74,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0b, 0x2e, 0x43, 0x6f, 0x6d, 0x6d, 0x69, 0x74, 0x6d,
+	0x65, 0x6e, 0x74, 0x48, 0x00, 0x52, 0x0a, 0x63, 0x6f, 0x6d, 0x6d, 0x69, 0x74, 0x6d, 0x65, 0x6e,
+	0x74, 0x12, 0x21, 0x0a, 0x05, 0x74, 0x68, 0x72, 0x6f, 0x77, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x09, 0x2e, 0x44, 0x69, 0x65, 0x54, 0x68, 0x72, 0x6f, 0x77, 0x48, 0x00, 0x52, 0x05, 0x74,
+	0x68, 0x72, 0x6f, 0x77, 0x12, 0x24, 0x0a, 0x07, 0x6f, 0x70, 0x65, 0x6e, 0x69, 0x6e, 0x67, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x08, 0x2e, 0x4f, 0x70, 0x65, 0x6e, 0x69, 0x6e, 0x67, 0x48,
+	0x00, 0x52, 0x07, 0x6f, 0x70, 0x65, 0x6e, 0x69, 0x6e, 0x67, 0x12, 0x24, 0x0a, 0x03, 0x61, 0x63,
+	0x6b, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x41, 0x63, 0x6b, 0x6e, 0x6f, 0x77,
+	0x6c, 0x65, 0x64, 0x67, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x48, 0x00, 0x52, 0x03, 0x61, 0x63, 0x6b,
+	0x12, 0x24, 0x0a, 0x07, 0x76, 0x65, 0x72, 0x64, 0x69, 0x63, 0x74, 0x18, 0x05, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x08, 0x2e, 0x56, 0x65, 0x72, 0x64, 0x69, 0x63, 0x74, 0x48, 0x00, 0x52, 0x07, 0x76,
+	0x65, 0x72, 0x64, 0x69, 0x63, 0x74, 0x42, 0x05, 0x0a, 0x03, 0x6d, 0x73, 0x67, 0x4a, 0x04, 0x08,
+	0x06, 0x10, 0x07, 0x22, 0x9b, 0x01, 0x0a, 0x0a, 0x43, 0x6f, 0x6d, 0x6d, 0x69, 0x74, 0x6d, 0x65,
+	0x6e, 0x74, 0x12, 0x0c, 0x0a, 0x01, 0x63, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x01, 0x63,
+	0x12, 0x1d, 0x0a, 0x0a, 0x73, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x5f, 0x69, 0x64, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x0c, 0x52, 0x09, 0x73, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x49, 0x64, 0x12,
+	0x14, 0x0a, 0x05, 0x72, 0x6f, 0x75, 0x6e, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x05,
+	0x72, 0x6f, 0x75, 0x6e, 0x64, 0x12, 0x2c, 0x0a, 0x0b, 0x72, 0x61, 0x6e, 0x67, 0x65, 0x5f, 0x70,
+	0x72, 0x6f, 0x6f, 0x66, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0b, 0x2e, 0x52, 0x61, 0x6e,
+	0x67, 0x65, 0x50, 0x72, 0x6f, 0x6f, 0x66, 0x52, 0x0a, 0x72, 0x61, 0x6e, 0x67, 0x65, 0x50, 0x72,
+	0x6f, 0x6f, 0x66, 0x12, 0x1c, 0x0a, 0x05, 0x70, 0x72, 0x6f, 0x6f, 0x66, 0x18, 0x05, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x06, 0x2e, 0x50, 0x72, 0x6f, 0x6f, 0x66, 0x52, 0x05, 0x70, 0x72, 0x6f, 0x6f,
+	0x66, 0x22, 0x35, 0x0a, 0x05, 0x50, 0x72, 0x6f, 0x6f, 0x66, 0x12, 0x0c, 0x0a, 0x01, 0x65, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x01, 0x65, 0x12, 0x0e, 0x0a, 0x02, 0x7a, 0x6d, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x0c, 0x52, 0x02, 0x7a, 0x6d, 0x12, 0x0e, 0x0a, 0x02, 0x7a, 0x72, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x0c, 0x52, 0x02, 0x7a, 0x72, 0x22, 0x28, 0x0a, 0x0a, 0x52, 0x61, 0x6e, 0x67,
+	0x65, 0x50, 0x72, 0x6f, 0x6f, 0x66, 0x12, 0x0c, 0x0a, 0x01, 0x65, 0x18, 0x01, 0x20, 0x03, 0x28,
+	0x0c, 0x52, 0x01, 0x65, 0x12, 0x0c, 0x0a, 0x01, 0x7a, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0c, 0x52,
+	0x01, 0x7a, 0x22, 0x5a, 0x0a, 0x07, 0x4f, 0x70, 0x65, 0x6e, 0x69, 0x6e, 0x67, 0x12, 0x0c, 0x0a,
+	0x01, 0x6d, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x01, 0x6d, 0x12, 0x0c, 0x0a, 0x01, 0x72,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x01, 0x72, 0x12, 0x1d, 0x0a, 0x0a, 0x73, 0x65, 0x73,
+	0x73, 0x69, 0x6f, 0x6e, 0x5f, 0x69, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x09, 0x73,
+	0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x49, 0x64, 0x12, 0x14, 0x0a, 0x05, 0x72, 0x6f, 0x75, 0x6e,
+	0x64, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x05, 0x72, 0x6f, 0x75, 0x6e, 0x64, 0x22, 0x51,
+	0x0a, 0x08, 0x44, 0x69, 0x65, 0x54, 0x68, 0x72, 0x6f, 0x77, 0x12, 0x10, 0x0a, 0x03, 0x76, 0x61,
+	0x6c, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x03, 0x76, 0x61, 0x6c, 0x12, 0x1d, 0x0a, 0x0a,
+	0x73, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c,
+	0x52, 0x09, 0x73, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x49, 0x64, 0x12, 0x14, 0x0a, 0x05, 0x72,
+	0x6f, 0x75, 0x6e, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x05, 0x72, 0x6f, 0x75, 0x6e,
+	0x64, 0x22, 0x58, 0x0a, 0x0f, 0x41, 0x63, 0x6b, 0x6e, 0x6f, 0x77, 0x6c, 0x65, 0x64, 0x67, 0x65,
+	0x6d, 0x65, 0x6e, 0x74, 0x12, 0x10, 0x0a, 0x03, 0x61, 0x63, 0x6b, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x08, 0x52, 0x03, 0x61, 0x63, 0x6b, 0x12, 0x1d, 0x0a, 0x0a, 0x73, 0x65, 0x73, 0x73, 0x69, 0x6f,
+	0x6e, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x09, 0x73, 0x65, 0x73, 0x73,
+	0x69, 0x6f, 0x6e, 0x49, 0x64, 0x12, 0x14, 0x0a, 0x05, 0x72, 0x6f, 0x75, 0x6e, 0x64, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x0d, 0x52, 0x05, 0x72, 0x6f, 0x75, 0x6e, 0x64, 0x22, 0x72, 0x0a, 0x07, 0x56,
+	0x65, 0x72, 0x64, 0x69, 0x63, 0x74, 0x12, 0x1a, 0x0a, 0x08, 0x63, 0x68, 0x65, 0x61, 0x74, 0x65,
+	0x72, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x09, 0x52, 0x08, 0x63, 0x68, 0x65, 0x61, 0x74, 0x65,
+	0x72, 0x73, 0x12, 0x16, 0x0a, 0x06, 0x64, 0x69, 0x67, 0x65, 0x73, 0x74, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x0c, 0x52, 0x06, 0x64, 0x69, 0x67, 0x65, 0x73, 0x74, 0x12, 0x1d, 0x0a, 0x0a, 0x73, 0x65,
+	0x73, 0x73, 0x69, 0x6f, 0x6e, 0x5f, 0x69, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x09,
+	0x73, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x49, 0x64, 0x12, 0x14, 0x0a, 0x05, 0x72, 0x6f, 0x75,
+	0x6e, 0x64, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x05, 0x72, 0x6f, 0x75, 0x6e, 0x64, 0x22,
+	0x54, 0x0a, 0x05, 0x41, 0x62, 0x6f, 0x72, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x72, 0x65, 0x61, 0x73,
+	0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e,
+	0x12, 0x1d, 0x0a, 0x0a, 0x73, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x5f, 0x69, 0x64, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x0c, 0x52, 0x09, 0x73, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x49, 0x64, 0x12,
+	0x14, 0x0a, 0x05, 0x72, 0x6f, 0x75, 0x6e, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x05,
+	0x72, 0x6f, 0x75, 0x6e, 0x64, 0x32, 0xa5, 0x01, 0x0a, 0x08, 0x44, 0x69, 0x63, 0x65, 0x47, 0x61,
+	0x6d, 0x65, 0x12, 0x1f, 0x0a, 0x05, 0x48, 0x65, 0x6c, 0x6c, 0x6f, 0x12, 0x09, 0x2e, 0x47, 0x72,
+	0x65, 0x65, 0x74, 0x69, 0x6e, 0x67, 0x1a, 0x09, 0x2e, 0x47, 0x72, 0x65, 0x65, 0x74, 0x69, 0x6e,
+	0x67, 0x22, 0x00, 0x12, 0x29, 0x0a, 0x09, 0x4e, 0x65, 0x67, 0x6f, 0x74, 0x69, 0x61, 0x74, 0x65,
+	0x12, 0x0c, 0x2e, 0x4e, 0x65, 0x67, 0x6f, 0x74, 0x69, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x1a, 0x0c,
+	0x2e, 0x4e, 0x65, 0x67, 0x6f, 0x74, 0x69, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x22, 0x00, 0x12, 0x28,
+	0x0a, 0x04, 0x50, 0x6c, 0x61, 0x79, 0x12, 0x0c, 0x2e, 0x47, 0x61, 0x6d, 0x65, 0x4d, 0x65, 0x73,
+	0x73, 0x61, 0x67, 0x65, 0x1a, 0x0c, 0x2e, 0x47, 0x61, 0x6d, 0x65, 0x4d, 0x65, 0x73, 0x73, 0x61,
+	0x67, 0x65, 0x22, 0x00, 0x28, 0x01, 0x30, 0x01, 0x12, 0x23, 0x0a, 0x05, 0x41, 0x62, 0x6f, 0x72,
+	0x74, 0x12, 0x06, 0x2e, 0x41, 0x62, 0x6f, 0x72, 0x74, 0x1a, 0x10, 0x2e, 0x41, 0x63, 0x6b, 0x6e,
+	0x6f, 0x77, 0x6c, 0x65, 0x64, 0x67, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x22, 0x00, 0x42, 0x29, 0x5a,
+	0x27, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x73, 0x61, 0x6d, 0x73,
+	0x61, 0x70, 0x74, 0x69, 0x2f, 0x73, 0x65, 0x63, 0x31, 0x2d, 0x68, 0x61, 0x6e, 0x64, 0x69, 0x6e,
+	0x2d, 0x30, 0x32, 0x2f, 0x67, 0x72, 0x70, 0x63, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -252,23 +1027,43 @@ func file_grpc_main_proto_rawDescGZIP() []byte {
 	return file_grpc_main_proto_rawDescData
 }
 
-var file_grpc_main_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
+var file_grpc_main_proto_msgTypes = make([]protoimpl.MessageInfo, 12)
 var file_grpc_main_proto_goTypes = []interface{}{
-	(*Commitment)(nil),      // 0: Commitment
-	(*Opening)(nil),         // 1: Opening
-	(*DieThrow)(nil),        // 2: DieThrow
-	(*Acknowledgement)(nil), // 3: Acknowledgement
+	(*Greeting)(nil),        // 0: Greeting
+	(*Negotiation)(nil),     // 1: Negotiation
+	(*Params)(nil),          // 2: Params
+	(*GameMessage)(nil),     // 3: GameMessage
+	(*Commitment)(nil),      // 4: Commitment
+	(*Proof)(nil),           // 5: Proof
+	(*RangeProof)(nil),      // 6: RangeProof
+	(*Opening)(nil),         // 7: Opening
+	(*DieThrow)(nil),        // 8: DieThrow
+	(*Acknowledgement)(nil), // 9: Acknowledgement
+	(*Verdict)(nil),         // 10: Verdict
+	(*Abort)(nil),           // 11: Abort
 }
 var file_grpc_main_proto_depIdxs = []int32{
-	0, // 0: DiceGame.SendCommitment:input_type -> Commitment
-	1, // 1: DiceGame.SendOpening:input_type -> Opening
-	2, // 2: DiceGame.SendCommitment:output_type -> DieThrow
-	3, // 3: DiceGame.SendOpening:output_type -> Acknowledgement
-	2, // [2:4] is the sub-list for method output_type
-	0, // [0:2] is the sub-list for method input_type
-	0, // [0:0] is the sub-list for extension type_name
-	0, // [0:0] is the sub-list for extension extendee
-	0, // [0:0] is the sub-list for field type_name
+	2,  // 0: Negotiation.params:type_name -> Params
+	4,  // 1: GameMessage.commitment:type_name -> Commitment
+	8,  // 2: GameMessage.throw:type_name -> DieThrow
+	7,  // 3: GameMessage.opening:type_name -> Opening
+	9,  // 4: GameMessage.ack:type_name -> Acknowledgement
+	10, // 5: GameMessage.verdict:type_name -> Verdict
+	6,  // 6: Commitment.range_proof:type_name -> RangeProof
+	5,  // 7: Commitment.proof:type_name -> Proof
+	0,  // 8: DiceGame.Hello:input_type -> Greeting
+	1,  // 9: DiceGame.Negotiate:input_type -> Negotiation
+	3,  // 10: DiceGame.Play:input_type -> GameMessage
+	11, // 11: DiceGame.Abort:input_type -> Abort
+	0,  // 12: DiceGame.Hello:output_type -> Greeting
+	1,  // 13: DiceGame.Negotiate:output_type -> Negotiation
+	3,  // 14: DiceGame.Play:output_type -> GameMessage
+	9,  // 15: DiceGame.Abort:output_type -> Acknowledgement
+	12, // [12:16] is the sub-list for method output_type
+	8,  // [8:12] is the sub-list for method input_type
+	8,  // [8:8] is the sub-list for extension type_name
+	8,  // [8:8] is the sub-list for extension extendee
+	0,  // [0:8] is the sub-list for field type_name
 }
 
 func init() { file_grpc_main_proto_init() }
@@ -278,7 +1073,7 @@ func file_grpc_main_proto_init() {
 	}
 	if !protoimpl.UnsafeEnabled {
 		file_grpc_main_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*Commitment); i {
+			switch v := v.(*Greeting); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -290,7 +1085,7 @@ func file_grpc_main_proto_init() {
 			}
 		}
 		file_grpc_main_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*Opening); i {
+			switch v := v.(*Negotiation); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -302,7 +1097,7 @@ func file_grpc_main_proto_init() {
 			}
 		}
 		file_grpc_main_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*DieThrow); i {
+			switch v := v.(*Params); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -314,6 +1109,78 @@ func file_grpc_main_proto_init() {
 			}
 		}
 		file_grpc_main_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GameMessage); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_grpc_main_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Commitment); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_grpc_main_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Proof); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_grpc_main_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RangeProof); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_grpc_main_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Opening); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_grpc_main_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DieThrow); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_grpc_main_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*Acknowledgement); i {
 			case 0:
 				return &v.state
@@ -325,6 +1192,37 @@ func file_grpc_main_proto_init() {
 				return nil
 			}
 		}
+		file_grpc_main_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Verdict); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_grpc_main_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Abort); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	file_grpc_main_proto_msgTypes[3].OneofWrappers = []interface{}{
+		(*GameMessage_Commitment)(nil),
+		(*GameMessage_Throw)(nil),
+		(*GameMessage_Opening)(nil),
+		(*GameMessage_Ack)(nil),
+		(*GameMessage_Verdict)(nil),
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
@@ -332,7 +1230,7 @@ func file_grpc_main_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_grpc_main_proto_rawDesc,
 			NumEnums:      0,
-			NumMessages:   4,
+			NumMessages:   12,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -18,8 +18,15 @@ const _ = grpc.SupportPackageIsVersion7
 //
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type DiceGameClient interface {
-	SendCommitment(ctx context.Context, in *Commitment, opts ...grpc.CallOption) (*DieThrow, error)
-	SendOpening(ctx context.Context, in *Opening, opts ...grpc.CallOption) (*Acknowledgement, error)
+	// Checks that a player is up and serving before we negotiate with them
+	Hello(ctx context.Context, in *Greeting, opts ...grpc.CallOption) (*Greeting, error)
+	Negotiate(ctx context.Context, in *Negotiation, opts ...grpc.CallOption) (*Negotiation, error)
+	// Every pair of players plays over a single stream, opened by the player
+	// whose name sorts first
+	Play(ctx context.Context, opts ...grpc.CallOption) (DiceGame_PlayClient, error)
+	// Tells a player why we leave the game early. It works even when the
+	// Play streams are broken.
+	Abort(ctx context.Context, in *Abort, opts ...grpc.CallOption) (*Acknowledgement, error)
 }
 
 type diceGameClient struct {
@@ -30,18 +37,58 @@ func NewDiceGameClient(cc grpc.ClientConnInterface) DiceGameClient {
 	return &diceGameClient{cc}
 }
 
-func (c *diceGameClient) SendCommitment(ctx context.Context, in *Commitment, opts ...grpc.CallOption) (*DieThrow, error) {
-	out := new(DieThrow)
-	err := c.cc.Invoke(ctx, "/DiceGame/SendCommitment", in, out, opts...)
+func (c *diceGameClient) Hello(ctx context.Context, in *Greeting, opts ...grpc.CallOption) (*Greeting, error) {
+	out := new(Greeting)
+	err := c.cc.Invoke(ctx, "/DiceGame/Hello", in, out, opts...)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-func (c *diceGameClient) SendOpening(ctx context.Context, in *Opening, opts ...grpc.CallOption) (*Acknowledgement, error) {
+func (c *diceGameClient) Negotiate(ctx context.Context, in *Negotiation, opts ...grpc.CallOption) (*Negotiation, error) {
+	out := new(Negotiation)
+	err := c.cc.Invoke(ctx, "/DiceGame/Negotiate", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *diceGameClient) Play(ctx context.Context, opts ...grpc.CallOption) (DiceGame_PlayClient, error) {
+	stream, err := c.cc.NewStream(ctx, &DiceGame_ServiceDesc.Streams[0], "/DiceGame/Play", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &diceGamePlayClient{stream}
+	return x, nil
+}
+
+type DiceGame_PlayClient interface {
+	Send(*GameMessage) error
+	Recv() (*GameMessage, error)
+	grpc.ClientStream
+}
+
+type diceGamePlayClient struct {
+	grpc.ClientStream
+}
+
+func (x *diceGamePlayClient) Send(m *GameMessage) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *diceGamePlayClient) Recv() (*GameMessage, error) {
+	m := new(GameMessage)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *diceGameClient) Abort(ctx context.Context, in *Abort, opts ...grpc.CallOption) (*Acknowledgement, error) {
 	out := new(Acknowledgement)
-	err := c.cc.Invoke(ctx, "/DiceGame/SendOpening", in, out, opts...)
+	err := c.cc.Invoke(ctx, "/DiceGame/Abort", in, out, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -52,8 +99,15 @@ func (c *diceGameClient) SendOpening(ctx context.Context, in *Opening, opts ...g
 // All implementations must embed UnimplementedDiceGameServer
 // for forward compatibility
 type DiceGameServer interface {
-	SendCommitment(context.Context, *Commitment) (*DieThrow, error)
-	SendOpening(context.Context, *Opening) (*Acknowledgement, error)
+	// Checks that a player is up and serving before we negotiate with them
+	Hello(context.Context, *Greeting) (*Greeting, error)
+	Negotiate(context.Context, *Negotiation) (*Negotiation, error)
+	// Every pair of players plays over a single stream, opened by the player
+	// whose name sorts first
+	Play(DiceGame_PlayServer) error
+	// Tells a player why we leave the game early. It works even when the
+	// Play streams are broken.
+	Abort(context.Context, *Abort) (*Acknowledgement, error)
 	mustEmbedUnimplementedDiceGameServer()
 }
 
@@ -61,11 +115,17 @@ type DiceGameServer interface {
 type UnimplementedDiceGameServer struct {
 }
 
-func (UnimplementedDiceGameServer) SendCommitment(context.Context, *Commitment) (*DieThrow, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method SendCommitment not implemented")
+func (UnimplementedDiceGameServer) Hello(context.Context, *Greeting) (*Greeting, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Hello not implemented")
 }
-func (UnimplementedDiceGameServer) SendOpening(context.Context, *Opening) (*Acknowledgement, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method SendOpening not implemented")
+func (UnimplementedDiceGameServer) Negotiate(context.Context, *Negotiation) (*Negotiation, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Negotiate not implemented")
+}
+func (UnimplementedDiceGameServer) Play(DiceGame_PlayServer) error {
+	return status.Errorf(codes.Unimplemented, "method Play not implemented")
+}
+func (UnimplementedDiceGameServer) Abort(context.Context, *Abort) (*Acknowledgement, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Abort not implemented")
 }
 func (UnimplementedDiceGameServer) mustEmbedUnimplementedDiceGameServer() {}
 
@@ -80,38 +140,82 @@ func RegisterDiceGameServer(s grpc.ServiceRegistrar, srv DiceGameServer) {
 	s.RegisterService(&DiceGame_ServiceDesc, srv)
 }
 
-func _DiceGame_SendCommitment_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(Commitment)
+func _DiceGame_Hello_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Greeting)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(DiceGameServer).SendCommitment(ctx, in)
+		return srv.(DiceGameServer).Hello(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/DiceGame/SendCommitment",
+		FullMethod: "/DiceGame/Hello",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(DiceGameServer).SendCommitment(ctx, req.(*Commitment))
+		return srv.(DiceGameServer).Hello(ctx, req.(*Greeting))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _DiceGame_SendOpening_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(Opening)
+func _DiceGame_Negotiate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Negotiation)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(DiceGameServer).SendOpening(ctx, in)
+		return srv.(DiceGameServer).Negotiate(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/DiceGame/SendOpening",
+		FullMethod: "/DiceGame/Negotiate",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(DiceGameServer).SendOpening(ctx, req.(*Opening))
+		return srv.(DiceGameServer).Negotiate(ctx, req.(*Negotiation))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DiceGame_Play_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(DiceGameServer).Play(&diceGamePlayServer{stream})
+}
+
+type DiceGame_PlayServer interface {
+	Send(*GameMessage) error
+	Recv() (*GameMessage, error)
+	grpc.ServerStream
+}
+
+type diceGamePlayServer struct {
+	grpc.ServerStream
+}
+
+func (x *diceGamePlayServer) Send(m *GameMessage) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *diceGamePlayServer) Recv() (*GameMessage, error) {
+	m := new(GameMessage)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _DiceGame_Abort_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Abort)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DiceGameServer).Abort(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/DiceGame/Abort",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DiceGameServer).Abort(ctx, req.(*Abort))
 	}
 	return interceptor(ctx, in, info, handler)
 }
@@ -124,14 +228,25 @@ var DiceGame_ServiceDesc = grpc.ServiceDesc{
 	HandlerType: (*DiceGameServer)(nil),
 	Methods: []grpc.MethodDesc{
 		{
-			MethodName: "SendCommitment",
-			Handler:    _DiceGame_SendCommitment_Handler,
+			MethodName: "Hello",
+			Handler:    _DiceGame_Hello_Handler,
+		},
+		{
+			MethodName: "Negotiate",
+			Handler:    _DiceGame_Negotiate_Handler,
 		},
 		{
-			MethodName: "SendOpening",
-			Handler:    _DiceGame_SendOpening_Handler,
+			MethodName: "Abort",
+			Handler:    _DiceGame_Abort_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Play",
+			Handler:       _DiceGame_Play_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
 		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "grpc/main.proto",
 }
@@ -2,107 +2,62 @@ package main
 
 import (
 	"context"
-	"crypto/rand"
-	"crypto/tls"
-	"crypto/x509"
-	"encoding/pem"
 	"flag"
-	"fmt"
 	"log"
-	"math/big"
 	"net"
 	"os"
+	"os/signal"
+	"strings"
+	"syscall"
 	"time"
 
+	"github.com/samsapti/sec1-handin-02/commit"
+	"github.com/samsapti/sec1-handin-02/dice"
 	pb "github.com/samsapti/sec1-handin-02/grpc"
 	"github.com/samsapti/sec1-handin-02/pedersen"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/backoff"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
 )
 
-const rounds int = 3
-
 var (
-	commChan    chan *pb.Commitment      = make(chan *pb.Commitment, 1)
-	openingChan chan *pb.Opening         = make(chan *pb.Opening, 1)
-	throwChan   chan *pb.DieThrow        = make(chan *pb.DieThrow, 1)
-	ackChan     chan *pb.Acknowledgement = make(chan *pb.Acknowledgement, 1)
-
-	name     *string = flag.String("name", "Alice", "Name of the player")
-	ownAddr  *string = flag.String("addr", "localhost:50051", "gRPC listen address. Format: [host]:port")
-	peerAddr *string = flag.String("peer_addr", "localhost:50052", "Peer's gRPC listen address. Format: [host]:port")
+	name          *string        = flag.String("name", "Alice", "Name of the player")
+	ownAddr       *string        = flag.String("addr", "localhost:50051", "gRPC listen address. Format: [host]:port")
+	peerAddrs     *string        = flag.String("peers", "localhost:50052", "Comma-separated gRPC listen addresses of the other players. Format: [host]:port,...")
+	group         *string        = flag.String("group", "modp2048", "Group for Pedersen commitments. One of: modp2048, ristretto255")
+	seed          *string        = flag.String("seed", "sec1-handin-02", "Public seed from which the commitment generators are derived")
+	schemeName    *string        = flag.String("commitment", commit.NamePedersen, "Commitment scheme. One of: pedersen, hmac-sha256, sha3-256")
+	rounds        *uint          = flag.Uint("rounds", 3, "Number of rounds to play")
+	sides         *uint          = flag.Uint("sides", 6, "Number of sides of the die, from 2 to 1000")
+	mode          *string        = flag.String("mode", modeClassic, "Protocol mode. One of: classic (two players, the starting player commits), symmetric (all players commit)")
+	certFile      *string        = flag.String("cert", "", "Our certificate. Defaults to certs/<name>.cert.pem")
+	keyFile       *string        = flag.String("key", "", "Our private key. Defaults to the certificate path with .key.pem")
+	trust         *string        = flag.String("trust", "certs/ca.cert.pem", "Comma-separated files or glob patterns of trusted peer certificates or CA bundles")
+	crl           *string        = flag.String("crl", "certs/ca.crl.pem", "Comma-separated files or glob patterns of certificate revocation lists. Empty to check none")
+	reload        *time.Duration = flag.Duration("reload", 5*time.Second, "How often to check the certificate, key, trust and CRL files for changes. 0 to never reload")
+	joinTimeout   *time.Duration = flag.Duration("join-timeout", 30*time.Second, "How long to wait for all other players to come online")
+	commitTimeout *time.Duration = flag.Duration("commit-timeout", 10*time.Second, "How long a peer may take to send their commitment")
+	throwTimeout  *time.Duration = flag.Duration("throw-timeout", 10*time.Second, "How long a peer may take to send their die throw")
+	openTimeout   *time.Duration = flag.Duration("open-timeout", 10*time.Second, "How long a peer may take to open their commitment")
+	ackTimeout    *time.Duration = flag.Duration("ack-timeout", 10*time.Second, "How long a peer may take to send their acknowledgement or verdict")
+	resumeTimeout *time.Duration = flag.Duration("resume-timeout", 30*time.Second, "How long to try to resume the session after losing the stream to a peer")
+	statusAddr    *string        = flag.String("status", "", "HTTP listen address of the status endpoint, which reports the certificates in use. Disabled if empty")
+	transcripts   *string        = flag.String("transcript", "transcripts", "Directory to write signed game transcripts to. Disabled if empty")
 )
 
-type server struct {
-	pb.UnimplementedDiceGameServer
-}
-
-func (s *server) SendCommitment(ctx context.Context, in *pb.Commitment) (*pb.DieThrow, error) {
-	commChan <- in
-	return <-throwChan, nil
-}
-
-func (s *server) SendOpening(ctx context.Context, in *pb.Opening) (*pb.Acknowledgement, error) {
-	openingChan <- in
-	return <-ackChan, nil
-}
-
-func getTLSConfig() *tls.Config {
-	certPool := x509.NewCertPool()
-	certs := []tls.Certificate{}
-
-	for _, v := range []string{"alice", "bob"} {
-		// Read certificate files
-		clientPemBytes, err := os.ReadFile(fmt.Sprintf("certs/%s.cert.pem", v))
-		if err != nil {
-			log.Fatalf("%s\n", err)
-		}
-
-		// Decode and parse certs
-		clientPemBlock, _ := pem.Decode(clientPemBytes)
-		clientCert, err := x509.ParseCertificate(clientPemBlock.Bytes)
-		if err != nil {
-			log.Fatalf("%s\n", err)
-		}
-
-		// Enforce client authentication and allow self-signed certs
-		clientCert.BasicConstraintsValid = true
-		clientCert.IsCA = true
-		clientCert.KeyUsage = x509.KeyUsageCertSign
-
-		// Add certs as client certs
-		certPool.AppendCertsFromPEM(clientPemBytes)
-
-		// Load certs as server certs
-		srvCert, err := tls.LoadX509KeyPair(fmt.Sprintf("certs/%s.cert.pem", v), fmt.Sprintf("certs/%s.key.pem", v))
-		if err != nil {
-			log.Fatalf("%s\n", err)
-		}
-		certs = append(certs, srvCert)
-	}
-
-	return &tls.Config{
-		Certificates: certs,
-		ClientAuth:   tls.RequireAndVerifyClientCert,
-		ClientCAs:    certPool,
-		RootCAs:      certPool,
-	}
-}
-
-func initPeer(client *pb.DiceGameClient) {
-	// Setup TLS tunnel
-	tlsCreds := credentials.NewTLS(getTLSConfig())
-
-	// Instantiate client
-	conn, err := grpc.Dial(*peerAddr, grpc.WithTransportCredentials(tlsCreds))
-	if err != nil {
-		log.Fatalf("Cannot start connection: %s\n", err)
-	}
-	*client = pb.NewDiceGameClient(conn)
-
+// initPeer serves the game until the context of the quitter is canceled, and
+// then stops gracefully.
+func initPeer(s *server, tlsCreds credentials.TransportCredentials) {
 	// Server connection info
-	srv := grpc.NewServer(grpc.Creds(tlsCreds))
-	pb.RegisterDiceGameServer(srv, &server{})
+	srv := grpc.NewServer(
+		grpc.Creds(tlsCreds),
+		grpc.UnaryInterceptor(s.unaryInterceptor),
+		grpc.StreamInterceptor(s.streamInterceptor),
+	)
+	pb.RegisterDiceGameServer(srv, s)
 
 	// Initialize listener
 	lis, err := net.Listen("tcp", *ownAddr)
@@ -110,103 +65,180 @@ func initPeer(client *pb.DiceGameClient) {
 		log.Fatalf("%s failed to listen: %s\n", *name, err)
 	}
 
+	go func() {
+		<-s.quit.ctx.Done()
+		srv.GracefulStop()
+	}()
+
 	// Server
 	log.Printf("%s listening at %s\n", *name, lis.Addr())
 	if err := srv.Serve(lis); err != nil {
 		log.Fatalf("%s failed to serve: %s\n", *name, err)
 	}
+	close(s.quit.stopped)
+}
+
+// joinPeer waits until the player at addr is up, retrying with exponential
+// backoff until ctx expires.
+func joinPeer(ctx context.Context, q *quitter, addr string, tlsCreds credentials.TransportCredentials) pb.DiceGameClient {
+	deadline, _ := ctx.Deadline()
+	log.Printf("%s waits for the player at %s, until %s\n", *name, addr, deadline.Format("15:04:05"))
+
+	conn, err := grpc.DialContext(ctx, addr,
+		grpc.WithTransportCredentials(tlsCreds),
+		grpc.WithUnaryInterceptor(unaryClientInterceptor),
+		grpc.WithStreamInterceptor(streamClientInterceptor),
+		grpc.WithBlock(),
+		grpc.WithReturnConnectionError(),
+		grpc.WithConnectParams(grpc.ConnectParams{
+			Backoff: backoff.Config{
+				BaseDelay:  100 * time.Millisecond,
+				Multiplier: 1.6,
+				Jitter:     0.2,
+				MaxDelay:   3 * time.Second,
+			},
+			MinConnectTimeout: time.Second,
+		}),
+	)
+	if err != nil {
+		q.quit(exitNetwork, true, "gave up waiting for the player at %s: %s", addr, err)
+	}
+	client := pb.NewDiceGameClient(conn)
+
+	hello, err := client.Hello(ctx, &pb.Greeting{Player: *name}, grpc.WaitForReady(true))
+	if err != nil {
+		q.quit(exitNetwork, true, "gave up waiting for the player at %s: %s", addr, err)
+	}
+	log.Printf("%s sees that %s is up at %s\n", *name, hello.Player, addr)
+
+	return client
 }
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "ca" {
+		runCA(os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "verify-transcript" {
+		runVerifyTranscript(os.Args[2:])
+		return
+	}
+
 	// Prepare
 	flag.Parse()
-	starts := false
-	ctx := context.Background()
+	q := newQuitter(context.Background())
+	addrs := strings.Split(*peerAddrs, ",")
 
-	if *name == "Alice" {
-		starts = true
+	if *rounds < 1 {
+		log.Fatalf("Error: need at least 1 round\n")
+	}
+	if err := dice.Check(uint64(*sides)); err != nil {
+		log.Fatalf("Error: %s\n", err)
+	}
+	if *mode != modeClassic && *mode != modeSymmetric {
+		log.Fatalf("Error: unknown mode %q\n", *mode)
+	}
+	if *mode == modeClassic && len(addrs) != 1 {
+		log.Fatalf("Error: classic mode needs exactly one peer\n")
 	}
 
-	// Setup connection
-	var client pb.DiceGameClient
-	go initPeer(&client)
-
-	// Wait for peer to come online
-	time.Sleep(2 * time.Second)
-
-	// Main game loop
-	for i := 0; i < rounds; i++ {
-		if starts {
-			log.Printf("%s starts round %d\n", *name, i+1)
-		}
+	grp, err := pedersen.GroupByName(*group)
+	if err != nil {
+		log.Fatalf("Error: %s\n", err)
+	}
+	params := pedersen.DeriveGenerators([]byte(*seed), grp)
+	scheme, err := commit.ByName(*schemeName, params)
+	if err != nil {
+		log.Fatalf("Error: %s\n", err)
+	}
+	offer := newOffer(params, scheme, len(addrs)+1)
 
-		throw, err := rand.Int(rand.Reader, big.NewInt(5))
+	// Setup TLS tunnel and server
+	creds, err := newCredStore()
+	if err != nil {
+		log.Fatalf("Error: %s\n", err)
+	}
+	if *reload > 0 {
+		go creds.watch(*reload)
+	}
+	if *statusAddr != "" {
+		go serveStatus(*statusAddr, creds)
+	}
+	tlsCreds := credentials.NewTLS(creds.clientConfig())
+	srv := newServer(params, offer, q)
+	go initPeer(srv, credentials.NewTLS(creds.serverConfig()))
+
+	// Tell the peers when we are interrupted
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		sig := <-sigs
+		q.quit(exitInterrupted, true, "received %s", sig)
+	}()
+
+	// Wait for peers to come online
+	joinCtx, cancel := context.WithTimeout(q.ctx, *joinTimeout)
+	defer cancel()
+
+	// Make sure all players run the same protocol with the same settings
+	clients := map[string]pb.DiceGameClient{}
+	offers := []*pb.Negotiation{offer}
+	for _, addr := range addrs {
+		client := joinPeer(joinCtx, q, addr, tlsCreds)
+
+		var p peer.Peer
+		peerOffer, err := client.Negotiate(joinCtx, offer, grpc.Peer(&p))
 		if err != nil {
-			log.Fatalf("Error: %s\n", err)
+			code := exitProtocol
+			if c := status.Code(err); c == codes.Unavailable || c == codes.DeadlineExceeded {
+				code = exitNetwork
+			}
+			q.quit(code, true, "negotiation with %s failed: %s", addr, err)
+		}
+		if err := checkPeer(peer.NewContext(q.ctx, &p), peerOffer.Player); err != nil {
+			q.quit(exitProtocol, true, "negotiation with %s failed: %s", addr, err)
+		}
+		if err := checkNegotiation(params, offer, peerOffer); err != nil {
+			q.quit(exitProtocol, true, "negotiation with %s failed: %s", addr, err)
+		}
+		player := canonical(peerOffer.Player)
+		if _, ok := clients[player]; ok {
+			q.quit(exitProtocol, true, "negotiation with %s failed: %s joined twice", addr, player)
 		}
 
-		m := throw.Uint64() + 1
-
-		if starts {
-			// Create commitment
-			r := pedersen.GetR()
-			c := pedersen.GetCommitment(m, r)
-
-			// Send commitment to peer and wait for die throw
-			log.Printf("%s sends commitment: %d\n", *name, c)
-			peerThrow, err := client.SendCommitment(ctx, &pb.Commitment{C: c})
-			if err != nil {
-				log.Fatalf("Error: %s\n", err)
-			}
-			log.Printf("%s receives peer's die throw: %d\n", *name, peerThrow.Val)
+		clients[player] = client
+		q.addPeer(player, client)
+		offers = append(offers, peerOffer)
+	}
 
-			// Send opening to peer
-			log.Printf("%s sends opening: (m: %d, r: %d)\n", *name, m, r)
-			peerAck, err := client.SendOpening(ctx, &pb.Opening{M: m, R: r})
-			if err != nil {
-				log.Fatalf("Error: %s\n", err)
-			}
-			log.Printf("%s receives acknowledgement: %t\n", *name, peerAck.Ack)
+	session := sessionID(offers)
+	q.setSession(session)
+	log.Printf("%s agreed on protocol v%d in %s mode: %d rounds with a d%d, %s commitments, generators from seed %q in group %s\n",
+		*name, offer.Version, offer.Mode, offer.Rounds, offer.Sides, offer.Scheme, params.Seed, grp.Name())
 
-			// Check peer's acknowledgement
-			if !peerAck.Ack {
-				log.Printf("%s got caught cheating, run!\n", *name)
-				time.Sleep(time.Second)
-				os.Exit(1)
-			}
+	// Record the game, signed with the key we joined with
+	tr, err := openTranscript(*transcripts, session, creds.certificate())
+	if err != nil {
+		log.Fatalf("Error: cannot write transcript: %s\n", err)
+	}
 
-			// Compute result
-			res := m ^ peerThrow.Val
-			log.Printf("%s computes final value: %d\n", *name, res)
+	// Main game loop
+	g := newGame(clients, srv, scheme, session, uint64(*sides), tr)
+	log.Printf("%s joins session %x with %s\n", *name, session, strings.Join(g.players, ", "))
+	tr.start(params, scheme, uint64(*sides), append([]string{*name}, g.players...))
+	for round := uint32(1); round <= uint32(*rounds); round++ {
+		q.setRound(round)
+		var res uint64
+		if *mode == modeSymmetric {
+			res = g.playSymmetric(round)
 		} else {
-			// Wait for commitment from peer
-			commitment := <-commChan
-			log.Printf("%s receives commitment: %d\n", *name, commitment.C)
-
-			throwChan <- &pb.DieThrow{Val: m}
-			log.Printf("%s sends their die throw: %d\n", *name, m)
-
-			opening := <-openingChan
-			log.Printf("%s receives opening: (m: %d, r: %d)\n", *name, opening.M, opening.R)
-
-			// Validate commitment from peer
-			if pedersen.ValidateCommitment(commitment.C, opening.M, opening.R) {
-				ackChan <- &pb.Acknowledgement{Ack: true}
-				log.Printf("%s confirms commitment is valid\n", *name)
-			} else {
-				ackChan <- &pb.Acknowledgement{Ack: false}
-				log.Printf("%s's opponent is cheating!\n", *name)
-				time.Sleep(time.Second)
-				os.Exit(1)
-			}
-
-			// Compute result
-			res := m ^ opening.M
-			log.Printf("%s computes final value: %d\n", *name, res)
+			res = g.playClassic(round)
 		}
+		log.Printf("%s computes final value: %d\n", *name, res)
+		tr.result(round, res)
 
-		// Switch turns
-		starts = !starts
 		time.Sleep(time.Second)
 	}
+	g.close()
+	q.stopServer()
 }
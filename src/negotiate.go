@@ -0,0 +1,137 @@
+package main
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"log"
+	"sort"
+
+	"github.com/samsapti/sec1-handin-02/commit"
+	pb "github.com/samsapti/sec1-handin-02/grpc"
+	"github.com/samsapti/sec1-handin-02/pedersen"
+)
+
+const (
+	// Version of the game protocol. Bump it on incompatible changes.
+	protocolVersion uint32 = 11
+
+	// Length of the random nonce each player contributes to the session ID
+	nonceLen int = 16
+
+	// Protocol modes
+	modeClassic   string = "classic"
+	modeSymmetric string = "symmetric"
+)
+
+func newOffer(pp *pedersen.Params, scheme commit.Scheme, players int) *pb.Negotiation {
+	nonce := make([]byte, nonceLen)
+	if _, err := rand.Read(nonce); err != nil {
+		log.Fatalf("Error: %s\n", err)
+	}
+
+	return &pb.Negotiation{
+		Version: protocolVersion,
+		Params:  encodeParams(pp),
+		Rounds:  uint32(*rounds),
+		Sides:   uint32(*sides),
+		Nonce:   nonce,
+		Player:  *name,
+		Mode:    *mode,
+		Players: uint32(players),
+		Scheme:  scheme.Name(),
+	}
+}
+
+// sessionID hashes the nonces of all players' offers, in a canonical order so
+// every player arrives at the same ID.
+func sessionID(offers []*pb.Negotiation) []byte {
+	nonces := [][]byte{}
+	for _, offer := range offers {
+		nonces = append(nonces, offer.Nonce)
+	}
+	sort.Slice(nonces, func(i, j int) bool {
+		return bytes.Compare(nonces[i], nonces[j]) < 0
+	})
+
+	hash := sha256.New()
+	for _, nonce := range nonces {
+		hash.Write(nonce)
+	}
+
+	return hash.Sum(nil)
+}
+
+// roundContext is what a player's commitments in a round are bound to. The
+// player is named in canonical form, so that every player binds to the same.
+func roundContext(session []byte, round uint32, player string) []byte {
+	ctx := binary.BigEndian.AppendUint32(append([]byte{}, session...), round)
+	return append(ctx, canonical(player)...)
+}
+
+// checkNegotiation verifies that the peer's offer matches ours in every
+// setting.
+func checkNegotiation(pp *pedersen.Params, own *pb.Negotiation, in *pb.Negotiation) error {
+	if in.Version != own.Version {
+		return fmt.Errorf("protocol version mismatch: %d != %d", in.Version, own.Version)
+	}
+	if in.Rounds != own.Rounds {
+		return fmt.Errorf("rounds mismatch: %d != %d", in.Rounds, own.Rounds)
+	}
+	if in.Sides != own.Sides {
+		return fmt.Errorf("die size mismatch: d%d != d%d", in.Sides, own.Sides)
+	}
+	if in.Players != own.Players {
+		return fmt.Errorf("player count mismatch: %d != %d", in.Players, own.Players)
+	}
+	if in.Mode != own.Mode {
+		return fmt.Errorf("mode mismatch: %s != %s", in.Mode, own.Mode)
+	}
+	if in.Scheme != own.Scheme {
+		return fmt.Errorf("commitment scheme mismatch: %s != %s", in.Scheme, own.Scheme)
+	}
+	if in.Player == "" || canonical(in.Player) == canonical(own.Player) {
+		return fmt.Errorf("invalid player name %q", in.Player)
+	}
+	if len(in.Nonce) != nonceLen || bytes.Equal(in.Nonce, own.Nonce) {
+		return fmt.Errorf("invalid session nonce")
+	}
+	if in.Params == nil {
+		return fmt.Errorf("missing commitment parameters")
+	}
+
+	return checkParams(pp, in.Params)
+}
+
+func encodeParams(pp *pedersen.Params) *pb.Params {
+	return &pb.Params{
+		Group: pp.Group.Name(),
+		Seed:  pp.Seed,
+		G:     pp.Group.Encode(pp.G),
+		H:     pp.Group.Encode(pp.H),
+	}
+}
+
+// checkParams verifies that the peer's parameters are derived from the same
+// seed in the same group as ours.
+func checkParams(own *pedersen.Params, in *pb.Params) error {
+	if in.Group != own.Group.Name() {
+		return fmt.Errorf("group mismatch: %s != %s", in.Group, own.Group.Name())
+	}
+	if !bytes.Equal(in.Seed, own.Seed) {
+		return fmt.Errorf("seed mismatch: %q != %q", in.Seed, own.Seed)
+	}
+
+	g, err := own.Group.Decode(in.G)
+	if err != nil {
+		return fmt.Errorf("invalid g: %s", err)
+	}
+	h, err := own.Group.Decode(in.H)
+	if err != nil {
+		return fmt.Errorf("invalid h: %s", err)
+	}
+
+	return pedersen.VerifyParams(&pedersen.Params{Group: own.Group, G: g, H: h}, in.Seed)
+}
@@ -0,0 +1,131 @@
+package pedersen
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"testing"
+)
+
+var testGroups = []Group{ModP2048(), Ristretto255()}
+
+// batch returns n commitments to die throws and their openings.
+func batch(pp *Params, n int) ([]Element, []Opening) {
+	cs := make([]Element, n)
+	os := make([]Opening, n)
+	for i := range cs {
+		os[i] = Opening{M: big.NewInt(int64(i % 6)), R: pp.GetR()}
+		cs[i] = pp.GetCommitment(os[i].M, os[i].R)
+	}
+
+	return cs, os
+}
+
+func TestBatchValidate(t *testing.T) {
+	for _, grp := range testGroups {
+		pp := DeriveGenerators([]byte("test"), grp)
+		cs, os := batch(pp, 10)
+
+		if err := pp.BatchValidate(cs, os); err != nil {
+			t.Errorf("%s: valid batch: %s", grp.Name(), err)
+		}
+		if err := pp.BatchValidate(nil, nil); err != nil {
+			t.Errorf("%s: empty batch: %s", grp.Name(), err)
+		}
+		if err := pp.BatchValidate(cs, os[1:]); err == nil {
+			t.Errorf("%s: batch with an opening missing validates", grp.Name())
+		}
+
+		for _, k := range []int{0, 4, 9} {
+			bad := append([]Opening{}, os...)
+			bad[k] = Opening{M: new(big.Int).Add(os[k].M, big.NewInt(1)), R: os[k].R}
+
+			var be *BatchError
+			err := pp.BatchValidate(cs, bad)
+			if !errors.As(err, &be) || be.Index != k {
+				t.Errorf("%s: bad opening %d: got %v, want &BatchError{Index: %d}", grp.Name(), k, err, k)
+			}
+		}
+
+		// Two bad openings that would cancel out without random weights
+		bad := append([]Opening{}, os...)
+		bad[2] = Opening{M: new(big.Int).Add(os[2].M, big.NewInt(1)), R: os[2].R}
+		bad[5] = Opening{M: new(big.Int).Sub(os[5].M, big.NewInt(1)), R: os[5].R}
+		var be *BatchError
+		if err := pp.BatchValidate(cs, bad); !errors.As(err, &be) || be.Index != 2 {
+			t.Errorf("%s: cancelling openings: got %v, want &BatchError{Index: 2}", grp.Name(), err)
+		}
+	}
+}
+
+func TestMultiScalarMult(t *testing.T) {
+	for _, grp := range testGroups {
+		pp := DeriveGenerators([]byte("test"), grp)
+		q := grp.Order()
+		es := []Element{pp.G, pp.H, grp.Generator(), grp.HashToPoint([]byte("x")), pp.G}
+		ks := []*big.Int{
+			big.NewInt(0),
+			big.NewInt(-5),
+			new(big.Int).Sub(q, big.NewInt(1)),
+			new(big.Int).Add(q, big.NewInt(7)),
+			pp.GetR(),
+		}
+
+		// Sum of the terms one by one, from the identity g^0
+		identity := grp.ScalarMult(pp.G, big.NewInt(0))
+		want := identity
+		for i := range es {
+			want = grp.Add(want, grp.ScalarMult(es[i], ks[i]))
+		}
+		if got := grp.MultiScalarMult(es, ks); !got.Equal(want) {
+			t.Errorf("%s: MultiScalarMult differs from the sum of ScalarMult", grp.Name())
+		}
+
+		// Each term on its own
+		for i := range es {
+			if got := grp.MultiScalarMult(es[i:i+1], ks[i:i+1]); !got.Equal(grp.ScalarMult(es[i], ks[i])) {
+				t.Errorf("%s: MultiScalarMult of term %d differs from ScalarMult", grp.Name(), i)
+			}
+		}
+
+		// Negative scalars invert
+		if got := grp.MultiScalarMult([]Element{pp.G, pp.G}, []*big.Int{big.NewInt(3), big.NewInt(-3)}); !got.Equal(identity) {
+			t.Errorf("%s: g^3 * g^-3 is not the identity", grp.Name())
+		}
+	}
+}
+
+func BenchmarkBatchValidate(b *testing.B) {
+	for _, grp := range testGroups {
+		pp := DeriveGenerators([]byte("bench"), grp)
+		for _, n := range []int{10, 100} {
+			cs, os := batch(pp, n)
+			b.Run(fmt.Sprintf("%s/%d", grp.Name(), n), func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					if err := pp.BatchValidate(cs, os); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
+
+// BenchmarkValidateLoop validates the same batches one opening at a time.
+func BenchmarkValidateLoop(b *testing.B) {
+	for _, grp := range testGroups {
+		pp := DeriveGenerators([]byte("bench"), grp)
+		for _, n := range []int{10, 100} {
+			cs, os := batch(pp, n)
+			b.Run(fmt.Sprintf("%s/%d", grp.Name(), n), func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					for j := range cs {
+						if !pp.ValidateOpening(cs[j], os[j]) {
+							b.Fatalf("opening %d does not validate", j)
+						}
+					}
+				}
+			})
+		}
+	}
+}
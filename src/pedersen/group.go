@@ -0,0 +1,55 @@
+package pedersen
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// Element is a member of a Group.
+type Element interface {
+	Equal(e Element) bool
+}
+
+// Group is a cyclic group of prime order in which commitments are computed.
+// The group operation is written additively, so for a multiplicative group
+// Add is multiplication and ScalarMult is exponentiation.
+type Group interface {
+	// Name identifies the group, e.g. in flags and during negotiation
+	Name() string
+
+	// Order returns the prime order q of the group
+	Order() *big.Int
+
+	// Generator returns the standard generator of the group
+	Generator() Element
+
+	ScalarMult(e Element, k *big.Int) Element
+	Add(a Element, b Element) Element
+
+	// Neg returns the inverse of e, so that Add(e, Neg(e)) is the identity
+	Neg(e Element) Element
+
+	// MultiScalarMult returns the sum of ks[i] * es[i], much faster than
+	// computing each term on its own
+	MultiScalarMult(es []Element, ks []*big.Int) Element
+
+	// Encode and Decode convert elements to and from their canonical byte
+	// representation. Decode rejects anything that is not a group element.
+	Encode(e Element) []byte
+	Decode(b []byte) (Element, error)
+
+	// HashToPoint maps data to an element with unknown discrete logarithm
+	HashToPoint(data []byte) Element
+}
+
+// GroupByName returns the group with the given name.
+func GroupByName(name string) (Group, error) {
+	switch name {
+	case "modp2048":
+		return ModP2048(), nil
+	case "ristretto255":
+		return Ristretto255(), nil
+	default:
+		return nil, fmt.Errorf("unknown group %q", name)
+	}
+}
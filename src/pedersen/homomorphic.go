@@ -0,0 +1,57 @@
+package pedersen
+
+import "math/big"
+
+// Pedersen commitments are additively homomorphic: g^m1 h^r1 * g^m2 h^r2 is a
+// commitment to m1 + m2 with randomness r1 + r2. This lets a player show that
+// several commitments add up to a total by opening only their sum.
+
+// Opening is the message and randomness that open a commitment.
+type Opening struct {
+	M *big.Int
+	R *big.Int
+}
+
+// Add returns a commitment to the sum of what c1 and c2 commit to.
+func (pp *Params) Add(c1 Element, c2 Element) Element {
+	return pp.Group.Add(c1, c2)
+}
+
+// ScalarMul returns a commitment to k times what c commits to.
+func (pp *Params) ScalarMul(c Element, k *big.Int) Element {
+	return pp.Group.ScalarMult(c, k)
+}
+
+// AddOpenings returns the opening of the sum of the commitments that the
+// openings open, as computed with Add.
+func (pp *Params) AddOpenings(openings ...Opening) Opening {
+	sum := Opening{M: new(big.Int), R: new(big.Int)}
+	for _, o := range openings {
+		sum.M.Add(sum.M, o.M)
+		sum.R.Add(sum.R, o.R)
+	}
+	sum.M.Mod(sum.M, pp.Group.Order())
+	sum.R.Mod(sum.R, pp.Group.Order())
+
+	return sum
+}
+
+// ScaleOpening returns the opening of ScalarMul(c, k), given the opening o of
+// c.
+func (pp *Params) ScaleOpening(o Opening, k *big.Int) Opening {
+	m := new(big.Int).Mul(o.M, k)
+	r := new(big.Int).Mul(o.R, k)
+
+	return Opening{M: m.Mod(m, pp.Group.Order()), R: r.Mod(r, pp.Group.Order())}
+}
+
+// Unbind removes the context ctx from a commitment made with Bind, so that
+// commitments bound to different contexts, such as rounds, can be added up.
+func (pp *Params) Unbind(c Element, ctx []byte) Element {
+	return pp.Group.Add(c, pp.Group.Neg(pp.Group.HashToPoint(append([]byte(ctxLabel), ctx...))))
+}
+
+// ValidateOpening reports whether o opens the unbound commitment c.
+func (pp *Params) ValidateOpening(c Element, o Opening) bool {
+	return pp.ValidateCommitment(c, o.M, o.R)
+}
@@ -0,0 +1,75 @@
+package pedersen
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+)
+
+func TestAdd(t *testing.T) {
+	for _, grp := range testGroups {
+		pp := DeriveGenerators([]byte("test"), grp)
+		cs, os := batch(pp, 5)
+
+		sum := cs[0]
+		for _, c := range cs[1:] {
+			sum = pp.Add(sum, c)
+		}
+		total := pp.AddOpenings(os...)
+		if !pp.ValidateOpening(sum, total) {
+			t.Errorf("%s: sum of throws does not open to the sum of openings", grp.Name())
+		}
+		if total.M.Int64() != 0+1+2+3+4 {
+			t.Errorf("%s: sum of throws is %s, want 10", grp.Name(), total.M)
+		}
+
+		// A claimed total off by one, with the right randomness
+		wrong := Opening{M: new(big.Int).Add(total.M, big.NewInt(1)), R: total.R}
+		if pp.ValidateOpening(sum, wrong) {
+			t.Errorf("%s: sum of throws opens to a wrong total", grp.Name())
+		}
+		if pp.ValidateOpening(sum, pp.AddOpenings(os[1:]...)) {
+			t.Errorf("%s: sum of throws opens without one of them", grp.Name())
+		}
+	}
+}
+
+func TestAddBound(t *testing.T) {
+	for _, grp := range testGroups {
+		pp := DeriveGenerators([]byte("test"), grp)
+		cs, os := batch(pp, 3)
+
+		// Commitments bound to different rounds add up once unbound
+		sum := pp.Unbind(pp.Bind(cs[0], []byte("round 0")), []byte("round 0"))
+		for i := 1; i < len(cs); i++ {
+			ctx := []byte(fmt.Sprintf("round %d", i))
+			sum = pp.Add(sum, pp.Unbind(pp.Bind(cs[i], ctx), ctx))
+		}
+		if !pp.ValidateOpening(sum, pp.AddOpenings(os...)) {
+			t.Errorf("%s: sum of unbound throws does not open to the sum of openings", grp.Name())
+		}
+	}
+}
+
+func TestScalarMul(t *testing.T) {
+	for _, grp := range testGroups {
+		pp := DeriveGenerators([]byte("test"), grp)
+		cs, os := batch(pp, 4)
+
+		for _, k := range []*big.Int{big.NewInt(0), big.NewInt(1), big.NewInt(7), pp.GetR()} {
+			if !pp.ValidateOpening(pp.ScalarMul(cs[3], k), pp.ScaleOpening(os[3], k)) {
+				t.Errorf("%s: %s times a throw does not open to the scaled opening", grp.Name(), k)
+			}
+		}
+
+		k := big.NewInt(7)
+		scaled := pp.ScaleOpening(os[3], k)
+		wrong := Opening{M: new(big.Int).Add(scaled.M, big.NewInt(1)), R: scaled.R}
+		if pp.ValidateOpening(pp.ScalarMul(cs[3], k), wrong) {
+			t.Errorf("%s: scaled throw opens to a wrong value", grp.Name())
+		}
+		if pp.ValidateOpening(pp.ScalarMul(cs[3], k), os[3]) {
+			t.Errorf("%s: scaled throw opens with the unscaled opening", grp.Name())
+		}
+	}
+}
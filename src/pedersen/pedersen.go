@@ -2,40 +2,82 @@ package pedersen
 
 import (
 	"crypto/rand"
+	"errors"
 	"log"
 	"math/big"
 )
 
+// Domain separation labels for deriving the generators from a seed, and for
+// binding commitments to a context
 const (
-	p uint64 = 6661
-	g uint64 = 666
-	h uint64 = 426
+	gLabel   string = "sec1-handin-02/pedersen/g/"
+	hLabel   string = "sec1-handin-02/pedersen/h/"
+	ctxLabel string = "sec1-handin-02/pedersen/ctx/"
 )
 
-func pow(x uint64, y uint64) uint64 {
-	bigX := big.NewInt(int64(x))
-	bigY := big.NewInt(int64(y))
-	bigP := big.NewInt(int64(p))
+// Params holds the public parameters of the commitment scheme: a group of
+// prime order q, two generators g and h of it, and the seed they were derived
+// from.
+type Params struct {
+	Group Group
+	Seed  []byte
+	G     Element
+	H     Element
+}
+
+// DeriveGenerators derives g and h from a public seed by hashing into grp.
+// Since both are outputs of a hash function, nobody knows log_g(h), which is
+// what makes the commitments binding.
+func DeriveGenerators(seed []byte, grp Group) *Params {
+	return &Params{
+		Group: grp,
+		Seed:  append([]byte{}, seed...),
+		G:     grp.HashToPoint(append([]byte(gLabel), seed...)),
+		H:     grp.HashToPoint(append([]byte(hLabel), seed...)),
+	}
+}
 
-	var power big.Int
-	power.Exp(bigX, bigY, bigP)
+// VerifyParams re-derives the generators from seed and checks that they match
+// those in pp.
+func VerifyParams(pp *Params, seed []byte) error {
+	want := DeriveGenerators(seed, pp.Group)
 
-	return power.Uint64()
+	if !pp.G.Equal(want.G) {
+		return errors.New("g is not derived from the seed")
+	}
+	if !pp.H.Equal(want.H) {
+		return errors.New("h is not derived from the seed")
+	}
+
+	return nil
 }
 
-func GetR() uint64 {
-	r, err := rand.Int(rand.Reader, big.NewInt(int64(p-1)))
+// GetR returns a uniformly random scalar in [0, q).
+func (pp *Params) GetR() *big.Int {
+	r, err := rand.Int(rand.Reader, pp.Group.Order())
 	if err != nil {
 		log.Fatalf("Error: %s\n", err)
 	}
 
-	return r.Uint64()
+	return r
+}
+
+// GetCommitment returns g^m * h^r, with m and r reduced mod q.
+func (pp *Params) GetCommitment(m *big.Int, r *big.Int) Element {
+	return pp.Group.Add(pp.Group.ScalarMult(pp.G, m), pp.Group.ScalarMult(pp.H, r))
+}
+
+func (pp *Params) ValidateCommitment(c Element, m *big.Int, r *big.Int) bool {
+	return c.Equal(pp.GetCommitment(m, r))
 }
 
-func GetCommitment(m uint64, r uint64) uint64 {
-	return pow(g, m) * pow(h, r) % p
+// Bind ties commitment c to a context, such as a session and round, by adding
+// a point hashed from the context. A bound commitment only validates in the
+// same context, so it cannot be replayed in another one.
+func (pp *Params) Bind(c Element, ctx []byte) Element {
+	return pp.Group.Add(c, pp.Group.HashToPoint(append([]byte(ctxLabel), ctx...)))
 }
 
-func ValidateCommitment(c uint64, m uint64, r uint64) bool {
-	return c == GetCommitment(m, r)
+func (pp *Params) ValidateBoundCommitment(c Element, ctx []byte, m *big.Int, r *big.Int) bool {
+	return c.Equal(pp.Bind(pp.GetCommitment(m, r), ctx))
 }
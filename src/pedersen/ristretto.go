@@ -0,0 +1,105 @@
+package pedersen
+
+import (
+	"crypto/sha512"
+	"errors"
+	"math/big"
+
+	"github.com/gtank/ristretto255"
+)
+
+// ristrettoOrder is the prime order l = 2^252 + 27742317777372353535851937790883648493
+const ristrettoOrder string = "7237005577332262213973186563042994240857116359379907606001950938285454250989"
+
+// RistrettoGroup is the prime-order ristretto255 group built on Curve25519.
+// Elements encode to 32 bytes.
+type RistrettoGroup struct {
+	l *big.Int
+}
+
+type ristrettoElement struct {
+	e *ristretto255.Element
+}
+
+func (e ristrettoElement) Equal(o Element) bool {
+	oe, ok := o.(ristrettoElement)
+	return ok && e.e.Equal(oe.e) == 1
+}
+
+// Ristretto255 returns the ristretto255 group.
+func Ristretto255() *RistrettoGroup {
+	l, _ := new(big.Int).SetString(ristrettoOrder, 10)
+	return &RistrettoGroup{l: l}
+}
+
+// scalar converts k to a ristretto255 scalar, reducing it mod l.
+func (grp *RistrettoGroup) scalar(k *big.Int) *ristretto255.Scalar {
+	buf := new(big.Int).Mod(k, grp.l).FillBytes(make([]byte, 32))
+
+	// Scalars are encoded little-endian
+	for i, j := 0, len(buf)-1; i < j; i, j = i+1, j-1 {
+		buf[i], buf[j] = buf[j], buf[i]
+	}
+
+	s := ristretto255.NewScalar()
+	if err := s.Decode(buf); err != nil {
+		panic(err)
+	}
+
+	return s
+}
+
+func (grp *RistrettoGroup) Name() string {
+	return "ristretto255"
+}
+
+func (grp *RistrettoGroup) Order() *big.Int {
+	return grp.l
+}
+
+func (grp *RistrettoGroup) Generator() Element {
+	return ristrettoElement{ristretto255.NewElement().Base()}
+}
+
+func (grp *RistrettoGroup) ScalarMult(e Element, k *big.Int) Element {
+	return ristrettoElement{ristretto255.NewElement().ScalarMult(grp.scalar(k), e.(ristrettoElement).e)}
+}
+
+func (grp *RistrettoGroup) Add(a Element, b Element) Element {
+	return ristrettoElement{ristretto255.NewElement().Add(a.(ristrettoElement).e, b.(ristrettoElement).e)}
+}
+
+// MultiScalarMult runs in variable time, so the scalars must not be secret.
+func (grp *RistrettoGroup) MultiScalarMult(es []Element, ks []*big.Int) Element {
+	points := make([]*ristretto255.Element, len(es))
+	scalars := make([]*ristretto255.Scalar, len(ks))
+	for i := range es {
+		points[i] = es[i].(ristrettoElement).e
+		scalars[i] = grp.scalar(ks[i])
+	}
+
+	return ristrettoElement{ristretto255.NewElement().VarTimeMultiScalarMult(scalars, points)}
+}
+
+func (grp *RistrettoGroup) Neg(e Element) Element {
+	return ristrettoElement{ristretto255.NewElement().Negate(e.(ristrettoElement).e)}
+}
+
+func (grp *RistrettoGroup) Encode(e Element) []byte {
+	return e.(ristrettoElement).e.Encode(nil)
+}
+
+func (grp *RistrettoGroup) Decode(b []byte) (Element, error) {
+	e := ristretto255.NewElement()
+	if err := e.Decode(b); err != nil {
+		return nil, errors.New("not a ristretto255 element")
+	}
+
+	return ristrettoElement{e}, nil
+}
+
+// HashToPoint uses the ristretto255 one-way map on a 64-byte SHA-512 digest.
+func (grp *RistrettoGroup) HashToPoint(data []byte) Element {
+	digest := sha512.Sum512(data)
+	return ristrettoElement{ristretto255.NewElement().FromUniformBytes(digest[:])}
+}
@@ -0,0 +1,180 @@
+package pedersen
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"log"
+	"math/big"
+	"strings"
+)
+
+// The 2048-bit MODP group from RFC 3526, section 3. The modulus is a safe
+// prime p = 2q + 1, and 2 generates the subgroup of order q.
+const (
+	modp2048 string = `
+		FFFFFFFF FFFFFFFF C90FDAA2 2168C234 C4C6628B 80DC1CD1
+		29024E08 8A67CC74 020BBEA6 3B139B22 514A0879 8E3404DD
+		EF9519B3 CD3A431B 302B0A6D F25F1437 4FE1356D 6D51C245
+		E485B576 625E7EC6 F44C42E9 A637ED6B 0BFF5CB6 F406B7ED
+		EE386BFB 5A899FA5 AE9F2411 7C4B1FE6 49286651 ECE45B3D
+		C2007CB8 A163BF05 98DA4836 1C55D39A 69163FA8 FD24CF5F
+		83655D23 DCA3AD96 1C62F356 208552BB 9ED52907 7096966D
+		670C354E 4ABC9804 F1746C08 CA18217C 32905E46 2E36CE3B
+		E39E772C 180E8603 9B2783A2 EC07A28F B5C55DF0 6F4C52C9
+		DE2BCBF6 95581718 3995497C EA956AE5 15D22618 98FA0510
+		15728E5A 8AACAA68 FFFFFFFF FFFFFFFF`
+	modp2048G int64 = 2
+)
+
+// ZpGroup is the subgroup of prime order q of the integers mod a safe prime
+// p = 2q + 1.
+type ZpGroup struct {
+	name string
+	P    *big.Int
+	Q    *big.Int
+	G    *big.Int
+}
+
+type zpElement struct {
+	x *big.Int
+}
+
+func (e zpElement) Equal(o Element) bool {
+	oe, ok := o.(zpElement)
+	return ok && e.x.Cmp(oe.x) == 0
+}
+
+// ModP2048 returns the 2048-bit MODP group from RFC 3526.
+func ModP2048() *ZpGroup {
+	p, ok := new(big.Int).SetString(strings.Join(strings.Fields(modp2048), ""), 16)
+	if !ok {
+		log.Fatalf("Error: invalid modulus\n")
+	}
+
+	return &ZpGroup{
+		name: "modp2048",
+		P:    p,
+		Q:    new(big.Int).Rsh(p, 1),
+		G:    big.NewInt(modp2048G),
+	}
+}
+
+func (grp *ZpGroup) pow(x *big.Int, y *big.Int) *big.Int {
+	return new(big.Int).Exp(x, y, grp.P)
+}
+
+func (grp *ZpGroup) Name() string {
+	return grp.name
+}
+
+func (grp *ZpGroup) Order() *big.Int {
+	return grp.Q
+}
+
+func (grp *ZpGroup) Generator() Element {
+	return zpElement{grp.G}
+}
+
+func (grp *ZpGroup) ScalarMult(e Element, k *big.Int) Element {
+	return zpElement{grp.pow(e.(zpElement).x, new(big.Int).Mod(k, grp.Q))}
+}
+
+func (grp *ZpGroup) Add(a Element, b Element) Element {
+	x := new(big.Int).Mul(a.(zpElement).x, b.(zpElement).x)
+	return zpElement{x.Mod(x, grp.P)}
+}
+
+// Width in bits of the windows of MultiScalarMult
+const zpWindow int = 4
+
+// MultiScalarMult computes the product of es[i]^ks[i] with Straus' method:
+// the squarings are shared between all terms, and each term only costs a
+// multiplication per window of its exponent.
+func (grp *ZpGroup) MultiScalarMult(es []Element, ks []*big.Int) Element {
+	bitLen := 0
+	scalars := make([]*big.Int, len(ks))
+	tables := make([][]*big.Int, len(es))
+	for i, e := range es {
+		scalars[i] = new(big.Int).Mod(ks[i], grp.Q)
+		if n := scalars[i].BitLen(); n > bitLen {
+			bitLen = n
+		}
+
+		// x^0 .. x^(2^w - 1)
+		x := e.(zpElement).x
+		tables[i] = make([]*big.Int, 1<<zpWindow)
+		tables[i][0] = big.NewInt(1)
+		for j := 1; j < len(tables[i]); j++ {
+			t := new(big.Int).Mul(tables[i][j-1], x)
+			tables[i][j] = t.Mod(t, grp.P)
+		}
+	}
+
+	acc := big.NewInt(1)
+	for win := (bitLen+zpWindow-1)/zpWindow - 1; win >= 0; win-- {
+		for b := 0; b < zpWindow; b++ {
+			acc.Mul(acc, acc).Mod(acc, grp.P)
+		}
+		for i, k := range scalars {
+			digit := 0
+			for b := zpWindow - 1; b >= 0; b-- {
+				digit = digit<<1 | int(k.Bit(win*zpWindow+b))
+			}
+			if digit != 0 {
+				acc.Mul(acc, tables[i][digit]).Mod(acc, grp.P)
+			}
+		}
+	}
+
+	return zpElement{acc}
+}
+
+func (grp *ZpGroup) Neg(e Element) Element {
+	return zpElement{new(big.Int).ModInverse(e.(zpElement).x, grp.P)}
+}
+
+func (grp *ZpGroup) Encode(e Element) []byte {
+	return e.(zpElement).x.FillBytes(make([]byte, (grp.P.BitLen()+7)/8))
+}
+
+func (grp *ZpGroup) Decode(b []byte) (Element, error) {
+	if len(b) != (grp.P.BitLen()+7)/8 {
+		return nil, errors.New("invalid element length")
+	}
+
+	// Only accept 0 < x < p with x^q = 1, i.e. members of the subgroup
+	x := new(big.Int).SetBytes(b)
+	if x.Sign() == 0 || x.Cmp(grp.P) >= 0 || grp.pow(x, grp.Q).Cmp(big.NewInt(1)) != 0 {
+		return nil, errors.New("not a subgroup element")
+	}
+
+	return zpElement{x}, nil
+}
+
+// HashToPoint hashes data to an integer mod p and squares it, which lands in
+// the subgroup of quadratic residues of order q.
+func (grp *ZpGroup) HashToPoint(data []byte) Element {
+	one := big.NewInt(1)
+
+	for ctr := uint32(0); ; ctr++ {
+		// Expand the hash to 128 bits more than p to make the bias negligible
+		buf := []byte{}
+		for i := uint32(0); len(buf) < (grp.P.BitLen()+128)/8; i++ {
+			hash := sha256.New()
+			binary.Write(hash, binary.BigEndian, ctr)
+			binary.Write(hash, binary.BigEndian, i)
+			hash.Write(data)
+			buf = hash.Sum(buf)
+		}
+
+		x := new(big.Int).SetBytes(buf)
+		x.Mod(x, grp.P)
+		x.Exp(x, big.NewInt(2), grp.P)
+
+		// Reject the identity, which is not a generator
+		if x.Sign() != 0 && x.Cmp(one) != 0 {
+			return zpElement{x}
+		}
+	}
+}
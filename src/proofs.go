@@ -0,0 +1,101 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/samsapti/sec1-handin-02/commit"
+	pb "github.com/samsapti/sec1-handin-02/grpc"
+	"github.com/samsapti/sec1-handin-02/zkp"
+)
+
+// Names of the proofs that go with a commitment, as recorded in transcripts
+const (
+	proofKnowledge string = "knowledge"
+	proofRange     string = "range"
+)
+
+var errMissingProof error = errors.New("missing")
+
+// prove attaches to c the proofs that the scheme supports: that we know its
+// opening, and that it commits to a contribution in [0, sides).
+func prove(scheme commit.Scheme, c *pb.Commitment, o commit.Opening, sides uint64, ctx []byte) error {
+	if kp, ok := scheme.(commit.KnowledgeProver); ok {
+		proof, err := kp.ProveKnowledge(c.C, o, ctx)
+		if err != nil {
+			return err
+		}
+		c.Proof = encodeKnowledgeProof(proof)
+	}
+	if rp, ok := scheme.(commit.RangeProver); ok {
+		proof, err := rp.ProveRange(c.C, o, sides, ctx)
+		if err != nil {
+			return err
+		}
+		c.RangeProof = encodeRangeProof(proof)
+	}
+
+	return nil
+}
+
+// checkProofs verifies the proofs that go with c, and reports the result of
+// each to record. Schemes that support a proof require it.
+func checkProofs(scheme commit.Scheme, c *pb.Commitment, sides uint64, ctx []byte, record func(proof string, err error)) error {
+	if kp, ok := scheme.(commit.KnowledgeProver); ok {
+		err := errMissingProof
+		if c.Proof != nil {
+			err = kp.VerifyKnowledge(c.C, decodeKnowledgeProof(c.Proof), ctx)
+		}
+		record(proofKnowledge, err)
+		if err != nil {
+			return fmt.Errorf("%s proof: %w", proofKnowledge, err)
+		}
+	}
+	if rp, ok := scheme.(commit.RangeProver); ok {
+		err := errMissingProof
+		if c.RangeProof != nil {
+			err = rp.VerifyRange(c.C, decodeRangeProof(c.RangeProof), sides, ctx)
+		}
+		record(proofRange, err)
+		if err != nil {
+			return fmt.Errorf("%s proof: %w", proofRange, err)
+		}
+	}
+
+	return nil
+}
+
+func encodeRangeProof(proof *zkp.RangeProof) *pb.RangeProof {
+	out := &pb.RangeProof{}
+	for i := range proof.E {
+		out.E = append(out.E, proof.E[i].Bytes())
+		out.Z = append(out.Z, proof.Z[i].Bytes())
+	}
+
+	return out
+}
+
+func decodeRangeProof(in *pb.RangeProof) *zkp.RangeProof {
+	proof := &zkp.RangeProof{}
+	for _, e := range in.E {
+		proof.E = append(proof.E, new(big.Int).SetBytes(e))
+	}
+	for _, z := range in.Z {
+		proof.Z = append(proof.Z, new(big.Int).SetBytes(z))
+	}
+
+	return proof
+}
+
+func encodeKnowledgeProof(proof *zkp.KnowledgeProof) *pb.Proof {
+	return &pb.Proof{E: proof.E.Bytes(), Zm: proof.Zm.Bytes(), Zr: proof.Zr.Bytes()}
+}
+
+func decodeKnowledgeProof(in *pb.Proof) *zkp.KnowledgeProof {
+	return &zkp.KnowledgeProof{
+		E:  new(big.Int).SetBytes(in.E),
+		Zm: new(big.Int).SetBytes(in.Zm),
+		Zr: new(big.Int).SetBytes(in.Zr),
+	}
+}
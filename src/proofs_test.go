@@ -0,0 +1,84 @@
+package main
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/samsapti/sec1-handin-02/commit"
+	pb "github.com/samsapti/sec1-handin-02/grpc"
+	"github.com/samsapti/sec1-handin-02/pedersen"
+	"github.com/samsapti/sec1-handin-02/zkp"
+	"google.golang.org/protobuf/proto"
+)
+
+func equalInts(a []*big.Int, b []*big.Int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Cmp(b[i]) != 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+func TestProofEncoding(t *testing.T) {
+	rp := &zkp.RangeProof{
+		E: []*big.Int{big.NewInt(0), big.NewInt(1), big.NewInt(1 << 40)},
+		Z: []*big.Int{big.NewInt(7), big.NewInt(0), big.NewInt(255)},
+	}
+	if got := decodeRangeProof(encodeRangeProof(rp)); !equalInts(got.E, rp.E) || !equalInts(got.Z, rp.Z) {
+		t.Errorf("range proof round-trip: got %v, want %v", got, rp)
+	}
+
+	kp := &zkp.KnowledgeProof{E: big.NewInt(0), Zm: big.NewInt(42), Zr: big.NewInt(1 << 50)}
+	got := decodeKnowledgeProof(encodeKnowledgeProof(kp))
+	if !equalInts([]*big.Int{got.E, got.Zm, got.Zr}, []*big.Int{kp.E, kp.Zm, kp.Zr}) {
+		t.Errorf("knowledge proof round-trip: got %v, want %v", got, kp)
+	}
+}
+
+func TestProofs(t *testing.T) {
+	const sides uint64 = 6
+	ctx := []byte("session 1 round 1 alice")
+	for _, grp := range []pedersen.Group{pedersen.ModP2048(), pedersen.Ristretto255()} {
+		scheme := commit.Pedersen(pedersen.DeriveGenerators([]byte("test"), grp))
+		c, o, err := scheme.Commit([]byte{4}, ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		msg := &pb.Commitment{C: c}
+		if err := prove(scheme, msg, o, sides, ctx); err != nil {
+			t.Fatalf("%s: prove: %s", grp.Name(), err)
+		}
+
+		// As the peer receives it
+		b, err := proto.Marshal(msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		received := &pb.Commitment{}
+		if err := proto.Unmarshal(b, received); err != nil {
+			t.Fatal(err)
+		}
+
+		recorded := map[string]error{}
+		record := func(proof string, err error) { recorded[proof] = err }
+		if err := checkProofs(scheme, received, sides, ctx, record); err != nil {
+			t.Errorf("%s: proofs do not verify after the round-trip: %s", grp.Name(), err)
+		}
+		for _, proof := range []string{proofKnowledge, proofRange} {
+			if err, ok := recorded[proof]; !ok || err != nil {
+				t.Errorf("%s: %s proof recorded as (%v, %t), want (nil, true)", grp.Name(), proof, err, ok)
+			}
+		}
+
+		received.RangeProof = nil
+		if err := checkProofs(scheme, received, sides, ctx, record); !errors.Is(err, errMissingProof) {
+			t.Errorf("%s: missing range proof: got %v, want %v", grp.Name(), err, errMissingProof)
+		}
+	}
+}
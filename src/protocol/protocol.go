@@ -0,0 +1,199 @@
+// Package protocol is the state machine of a round of the dice game, as seen
+// by one player towards one peer. Step is pure: it decides the next state and
+// what to send, and leaves the sending to the caller.
+package protocol
+
+import (
+	"bytes"
+	"fmt"
+
+	pb "github.com/samsapti/sec1-handin-02/grpc"
+)
+
+// Role of a player in a round
+type Role int
+
+const (
+	// Committer commits in a classic round, and opens after the peer's throw
+	Committer Role = iota
+	// Responder throws in the clear in a classic round
+	Responder
+	// Symmetric is a round where every player commits, opens and gives a
+	// verdict. The game sends to all peers at once, whenever they have all
+	// reached the same phase, so Begin and Step return no actions for it.
+	Symmetric
+)
+
+func (r Role) String() string {
+	return [...]string{"committer", "responder", "symmetric"}[r]
+}
+
+// Phase of a round, named after what we wait for from the peer
+type Phase int
+
+const (
+	AwaitCommit Phase = iota
+	AwaitThrow
+	AwaitOpen
+	AwaitAck
+	AwaitVerdict
+	Done
+	Aborted
+)
+
+func (p Phase) String() string {
+	return [...]string{"awaiting commitment", "awaiting throw", "awaiting opening", "awaiting acknowledgement", "awaiting verdict", "done", "aborted"}[p]
+}
+
+// Event is the kind of a message received from the peer
+type Event int
+
+const (
+	Commit Event = iota
+	Throw
+	Open
+	Ack
+	Verdict
+	Unknown
+)
+
+func (e Event) String() string {
+	return [...]string{"commitment", "throw", "opening", "acknowledgement", "verdict", "unknown message"}[e]
+}
+
+// Action is a message the player must send
+type Action int
+
+const (
+	SendCommitment Action = iota
+	SendThrow
+	SendOpening
+	SendAck
+)
+
+// State of a round with a single peer
+type State struct {
+	Role    Role
+	Phase   Phase
+	Session []byte
+	Round   uint32
+}
+
+type transition struct {
+	role  Role
+	phase Phase
+	event Event
+}
+
+type outcome struct {
+	phase   Phase
+	actions []Action
+}
+
+// Every legal transition. Anything else is a protocol violation.
+var transitions map[transition]outcome = map[transition]outcome{
+	{Committer, AwaitThrow, Throw}:     {AwaitAck, []Action{SendOpening}},
+	{Committer, AwaitAck, Ack}:         {Done, nil},
+	{Responder, AwaitCommit, Commit}:   {AwaitOpen, []Action{SendThrow}},
+	{Responder, AwaitOpen, Open}:       {Done, []Action{SendAck}},
+	{Symmetric, AwaitCommit, Commit}:   {AwaitOpen, nil},
+	{Symmetric, AwaitOpen, Open}:       {AwaitVerdict, nil},
+	{Symmetric, AwaitVerdict, Verdict}: {Done, nil},
+}
+
+// TransitionError is returned for a message that is not legal in a state.
+type TransitionError struct {
+	State State
+	Event Event
+}
+
+func (e *TransitionError) Error() string {
+	return fmt.Sprintf("unexpected %s while %s in round %d as %s", e.Event, e.State.Phase, e.State.Round, e.State.Role)
+}
+
+// ContextError is returned for a message from another session or round.
+type ContextError struct {
+	State   State
+	Session []byte
+	Round   uint32
+}
+
+func (e *ContextError) Error() string {
+	return fmt.Sprintf("message for session %x round %d in session %x round %d", e.Session, e.Round, e.State.Session, e.State.Round)
+}
+
+// Begin returns the initial state of a round, and what to send to start it.
+func Begin(role Role, session []byte, round uint32) (State, []Action) {
+	s := State{Role: role, Phase: AwaitCommit, Session: session, Round: round}
+	if role != Committer {
+		return s, nil
+	}
+
+	s.Phase = AwaitThrow
+	return s, []Action{SendCommitment}
+}
+
+// Step returns the state after receiving msg in state s, and what to send in
+// response. On error, the round is aborted.
+func Step(s State, msg *pb.GameMessage) (State, []Action, error) {
+	ev := EventOf(msg)
+	out, ok := transitions[transition{s.Role, s.Phase, ev}]
+	if !ok {
+		err := &TransitionError{State: s, Event: ev}
+		s.Phase = Aborted
+		return s, nil, err
+	}
+
+	session, round := MessageContext(msg)
+	if !bytes.Equal(session, s.Session) || round != s.Round {
+		err := &ContextError{State: s, Session: session, Round: round}
+		s.Phase = Aborted
+		return s, nil, err
+	}
+
+	s.Phase = out.phase
+	return s, out.actions, nil
+}
+
+// EventOf returns the kind of msg.
+func EventOf(msg *pb.GameMessage) Event {
+	switch msg.GetMsg().(type) {
+	case *pb.GameMessage_Commitment:
+		return Commit
+	case *pb.GameMessage_Throw:
+		return Throw
+	case *pb.GameMessage_Opening:
+		return Open
+	case *pb.GameMessage_Ack:
+		return Ack
+	case *pb.GameMessage_Verdict:
+		return Verdict
+	default:
+		return Unknown
+	}
+}
+
+// MessageContext returns the session and round msg belongs to.
+func MessageContext(msg *pb.GameMessage) ([]byte, uint32) {
+	var in interface {
+		GetSessionId() []byte
+		GetRound() uint32
+	}
+
+	switch m := msg.GetMsg().(type) {
+	case *pb.GameMessage_Commitment:
+		in = m.Commitment
+	case *pb.GameMessage_Throw:
+		in = m.Throw
+	case *pb.GameMessage_Opening:
+		in = m.Opening
+	case *pb.GameMessage_Ack:
+		in = m.Ack
+	case *pb.GameMessage_Verdict:
+		in = m.Verdict
+	default:
+		return nil, 0
+	}
+
+	return in.GetSessionId(), in.GetRound()
+}
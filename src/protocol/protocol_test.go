@@ -0,0 +1,141 @@
+package protocol
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	pb "github.com/samsapti/sec1-handin-02/grpc"
+)
+
+var (
+	testSession = []byte("session")
+	testRound   = uint32(3)
+)
+
+// message returns a message of kind ev in the given session and round.
+func message(ev Event, session []byte, round uint32) *pb.GameMessage {
+	switch ev {
+	case Commit:
+		return &pb.GameMessage{Msg: &pb.GameMessage_Commitment{Commitment: &pb.Commitment{SessionId: session, Round: round}}}
+	case Throw:
+		return &pb.GameMessage{Msg: &pb.GameMessage_Throw{Throw: &pb.DieThrow{SessionId: session, Round: round}}}
+	case Open:
+		return &pb.GameMessage{Msg: &pb.GameMessage_Opening{Opening: &pb.Opening{SessionId: session, Round: round}}}
+	case Ack:
+		return &pb.GameMessage{Msg: &pb.GameMessage_Ack{Ack: &pb.Acknowledgement{SessionId: session, Round: round}}}
+	case Verdict:
+		return &pb.GameMessage{Msg: &pb.GameMessage_Verdict{Verdict: &pb.Verdict{SessionId: session, Round: round}}}
+	default:
+		return &pb.GameMessage{}
+	}
+}
+
+// TestStep feeds every kind of message to every role in every phase, and
+// checks the outcome against the table of legal transitions.
+func TestStep(t *testing.T) {
+	for role := Committer; role <= Symmetric; role++ {
+		for phase := AwaitCommit; phase <= Aborted; phase++ {
+			for ev := Commit; ev <= Unknown; ev++ {
+				s := State{Role: role, Phase: phase, Session: testSession, Round: testRound}
+				next, actions, err := Step(s, message(ev, testSession, testRound))
+
+				want, legal := transitions[transition{role, phase, ev}]
+				if !legal {
+					var te *TransitionError
+					if !errors.As(err, &te) {
+						t.Errorf("%s %s got %s: error %v, want TransitionError", role, phase, ev, err)
+					} else if te.Event != ev || te.State.Phase != phase {
+						t.Errorf("%s %s got %s: error for %s in %s", role, phase, ev, te.Event, te.State.Phase)
+					}
+					if next.Phase != Aborted || actions != nil {
+						t.Errorf("%s %s got %s: now %s with %v, want aborted", role, phase, ev, next.Phase, actions)
+					}
+					continue
+				}
+
+				if err != nil {
+					t.Errorf("%s %s got %s: %s", role, phase, ev, err)
+				}
+				if next.Phase != want.phase || !reflect.DeepEqual(actions, want.actions) {
+					t.Errorf("%s %s got %s: now %s with %v, want %s with %v", role, phase, ev, next.Phase, actions, want.phase, want.actions)
+				}
+			}
+		}
+	}
+}
+
+// TestStepContext sends every legal message with the wrong session or round.
+func TestStepContext(t *testing.T) {
+	contexts := []struct {
+		session []byte
+		round   uint32
+	}{
+		{[]byte("other"), testRound},
+		{nil, testRound},
+		{testSession, testRound - 1},
+		{testSession, testRound + 1},
+	}
+
+	for tr := range transitions {
+		for _, c := range contexts {
+			s := State{Role: tr.role, Phase: tr.phase, Session: testSession, Round: testRound}
+			next, actions, err := Step(s, message(tr.event, c.session, c.round))
+
+			var ce *ContextError
+			if !errors.As(err, &ce) {
+				t.Errorf("%s %s got %s for session %q round %d: error %v, want ContextError", tr.role, tr.phase, tr.event, c.session, c.round, err)
+				continue
+			}
+			if ce.Round != c.round || string(ce.Session) != string(c.session) {
+				t.Errorf("ContextError for session %q round %d, want %q round %d", ce.Session, ce.Round, c.session, c.round)
+			}
+			if next.Phase != Aborted || actions != nil {
+				t.Errorf("%s %s got %s from another context: now %s with %v, want aborted", tr.role, tr.phase, tr.event, next.Phase, actions)
+			}
+		}
+	}
+}
+
+// TestRounds plays whole rounds through Begin and Step.
+func TestRounds(t *testing.T) {
+	tests := []struct {
+		role    Role
+		start   []Action
+		events  []Event
+		actions [][]Action
+	}{
+		{Committer, []Action{SendCommitment}, []Event{Throw, Ack}, [][]Action{{SendOpening}, nil}},
+		{Responder, nil, []Event{Commit, Open}, [][]Action{{SendThrow}, {SendAck}}},
+		{Symmetric, nil, []Event{Commit, Open, Verdict}, [][]Action{nil, nil, nil}},
+	}
+
+	for _, tt := range tests {
+		s, actions := Begin(tt.role, testSession, testRound)
+		if !reflect.DeepEqual(actions, tt.start) {
+			t.Errorf("%s begins with %v, want %v", tt.role, actions, tt.start)
+		}
+
+		for i, ev := range tt.events {
+			var err error
+			s, actions, err = Step(s, message(ev, testSession, testRound))
+			if err != nil {
+				t.Fatalf("%s got %s: %s", tt.role, ev, err)
+			}
+			if !reflect.DeepEqual(actions, tt.actions[i]) {
+				t.Errorf("%s got %s: sends %v, want %v", tt.role, ev, actions, tt.actions[i])
+			}
+		}
+		if s.Phase != Done {
+			t.Errorf("%s ends %s, want done", tt.role, s.Phase)
+		}
+	}
+}
+
+func TestEventOf(t *testing.T) {
+	for ev := Commit; ev <= Unknown; ev++ {
+		if got := EventOf(message(ev, testSession, testRound)); got != ev {
+			t.Errorf("EventOf(%s message) = %s", ev, got)
+		}
+	}
+}
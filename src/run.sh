@@ -1,6 +1,12 @@
 #!/usr/bin/env bash
 
-(cd certs && bash gen_certs.sh)
+if [ ! -f certs/ca.cert.pem ]; then
+    go run . ca init
+    go run . ca issue -name alice
+    go run . ca issue -name bob
+fi
 
-docker-compose up "$@"
-docker-compose down
\ No newline at end of file
+# The images hold the revocation list, so renew it and rebuild them
+go run . ca crl
+docker-compose up --build "$@"
+docker-compose down
@@ -0,0 +1,204 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"sync"
+
+	pb "github.com/samsapti/sec1-handin-02/grpc"
+	"github.com/samsapti/sec1-handin-02/pedersen"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// Metadata keys a player sends when opening a Play stream
+const (
+	playerKey  string = "player"
+	sessionKey string = "session"
+)
+
+// peerStream is a Play stream opened by a peer. The server hands it to the
+// game and keeps the stream open until done is closed.
+type peerStream struct {
+	player string
+	stream pb.DiceGame_PlayServer
+	done   chan struct{}
+}
+
+type server struct {
+	pb.UnimplementedDiceGameServer
+	params *pedersen.Params
+	offer  *pb.Negotiation
+	quit   *quitter
+
+	// Session state, guarded by mu. ready is closed once the session starts.
+	mu      sync.Mutex
+	offers  []*pb.Negotiation
+	peers   map[string]bool
+	streams map[string]chan peerStream
+	session []byte
+	ready   chan struct{}
+}
+
+func newServer(params *pedersen.Params, offer *pb.Negotiation, quit *quitter) *server {
+	return &server{
+		params:  params,
+		offer:   offer,
+		quit:    quit,
+		offers:  []*pb.Negotiation{offer},
+		peers:   map[string]bool{},
+		streams: map[string]chan peerStream{},
+		ready:   make(chan struct{}),
+	}
+}
+
+func (s *server) Hello(ctx context.Context, in *pb.Greeting) (*pb.Greeting, error) {
+	if identity := identityOf(ctx); identity != canonical(in.Player) {
+		return nil, status.Errorf(codes.PermissionDenied, "%s may not say hello as %s", identity, in.Player)
+	}
+	log.Printf("%s is up, and %s is waiting for us\n", *name, in.Player)
+
+	return &pb.Greeting{Player: *name}, nil
+}
+
+func (s *server) Negotiate(ctx context.Context, in *pb.Negotiation) (*pb.Negotiation, error) {
+	if err := checkNegotiation(s.params, s.offer, in); err != nil {
+		return nil, status.Errorf(codes.FailedPrecondition, "%s", err)
+	}
+	player := canonical(in.Player)
+	if identity := identityOf(ctx); identity != player {
+		return nil, status.Errorf(codes.PermissionDenied, "%s may not negotiate as %s", identity, in.Player)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.peers[player] {
+		return nil, status.Errorf(codes.AlreadyExists, "%s already negotiated", player)
+	}
+	s.peers[player] = true
+	s.offers = append(s.offers, in)
+
+	// The session starts once every player has made an offer
+	if len(s.offers) == int(s.offer.Players) {
+		s.session = sessionID(s.offers)
+		close(s.ready)
+	}
+
+	return s.offer, nil
+}
+
+func (s *server) Play(stream pb.DiceGame_PlayServer) error {
+	player, err := s.acceptStream(stream.Context())
+	if err != nil {
+		return err
+	}
+
+	done := make(chan struct{})
+	select {
+	case s.streamsOf(player) <- peerStream{player: player, stream: stream, done: done}:
+	case <-stream.Context().Done():
+		return nil
+	}
+
+	select {
+	case <-done:
+	case <-stream.Context().Done():
+	case <-s.quit.ctx.Done():
+	}
+
+	return nil
+}
+
+// Abort stops the game when a peer leaves it early. Only players we have
+// negotiated with, in either direction, may abort the game.
+func (s *server) Abort(ctx context.Context, in *pb.Abort) (*pb.Acknowledgement, error) {
+	player := identityOf(ctx)
+	if !s.negotiated(player) && !s.quit.hasPeer(player) {
+		return nil, status.Errorf(codes.PermissionDenied, "%s has not negotiated with %s", player, *name)
+	}
+
+	s.mu.Lock()
+	session := s.session
+	s.mu.Unlock()
+
+	// Once the session has started, an abort must name it
+	if session != nil && !bytes.Equal(in.SessionId, session) {
+		return nil, status.Errorf(codes.InvalidArgument, "unknown session %x", in.SessionId)
+	}
+
+	// Claim the quit before answering, so the peer closing its streams
+	// afterwards is not mistaken for a network failure
+	if s.quit.claim() {
+		log.Printf("%s aborts the game in round %d: %s\n", player, in.Round, in.Reason)
+		go s.quit.stop(exitPeerAborted, false, fmt.Sprintf("%s aborted", player))
+	}
+
+	return &pb.Acknowledgement{Ack: true, SessionId: in.SessionId, Round: in.Round}, nil
+}
+
+// negotiated reports whether player has negotiated with us.
+func (s *server) negotiated(player string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.peers[player]
+}
+
+// streamsOf returns where the streams opened by player are handed to the
+// game. A player opens a new stream to resume the session after losing one.
+func (s *server) streamsOf(player string) chan peerStream {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ch, ok := s.streams[player]
+	if !ok {
+		ch = make(chan peerStream, 1)
+		s.streams[player] = ch
+	}
+
+	return ch
+}
+
+// acceptStream waits for the session to start, and then accepts streams from
+// each negotiated player whose canonical name sorts before ours.
+func (s *server) acceptStream(ctx context.Context) (string, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	player, id := md.Get(playerKey), md.Get(sessionKey)
+	if len(player) != 1 || len(id) != 1 {
+		return "", status.Errorf(codes.InvalidArgument, "missing player or session")
+	}
+
+	select {
+	case <-s.ready:
+	case <-ctx.Done():
+		return "", status.FromContextError(ctx.Err()).Err()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if id[0] != hex.EncodeToString(s.session) {
+		return "", status.Errorf(codes.InvalidArgument, "unknown session %s", id[0])
+	}
+	identity := identityOf(ctx)
+	if identity != canonical(player[0]) {
+		return "", status.Errorf(codes.PermissionDenied, "%s may not play as %s", identity, player[0])
+	}
+	if !s.peers[identity] || identity >= canonical(*name) {
+		return "", status.Errorf(codes.PermissionDenied, "%s may not open a stream to %s", identity, *name)
+	}
+
+	return identity, nil
+}
+
+// startsRound reports whether this player commits first in the given round
+// of a classic game against peer, named in canonical form. The player whose
+// canonical name sorts first starts the odd rounds, and the other player the
+// even ones.
+func startsRound(round uint32, peer string) bool {
+	return (canonical(*name) < peer) == (round%2 == 1)
+}
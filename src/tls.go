@@ -0,0 +1,211 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+)
+
+// trustStore holds the trusted CAs and their revocation lists.
+type trustStore struct {
+	roots *x509.CertPool
+	crls  []*x509.RevocationList
+}
+
+// loadTrust reads the comma-separated files or glob patterns of trusted
+// certificates, and of revocation lists.
+func loadTrust(trustFiles string, crlFiles string) (*trustStore, error) {
+	t := &trustStore{roots: x509.NewCertPool()}
+
+	files, err := globAll(trustFiles)
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range files {
+		pemBytes, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		if !t.roots.AppendCertsFromPEM(pemBytes) {
+			return nil, fmt.Errorf("no certificates in %s", file)
+		}
+	}
+
+	files, err = globAll(crlFiles)
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range files {
+		pemBytes, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		block, _ := pem.Decode(pemBytes)
+		if block == nil {
+			return nil, fmt.Errorf("no revocation list in %s", file)
+		}
+		list, err := x509.ParseRevocationList(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+		t.crls = append(t.crls, list)
+	}
+
+	return t, nil
+}
+
+func globAll(patterns string) ([]string, error) {
+	files := []string{}
+	if patterns == "" {
+		return files, nil
+	}
+
+	for _, pattern := range strings.Split(patterns, ",") {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, err
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("no files match %s", pattern)
+		}
+		files = append(files, matches...)
+	}
+
+	return files, nil
+}
+
+// verify checks that the peer's certificate is valid now, chains to a trusted
+// CA for the given usage (and DNS name, if any), and is not revoked.
+func (t *trustStore) verify(cs tls.ConnectionState, usage x509.ExtKeyUsage, dnsName string) error {
+	err := t.check(cs, usage, dnsName)
+	if err != nil {
+		log.Printf("%s rejects peer certificate: %s\n", *name, err)
+	}
+
+	return err
+}
+
+func (t *trustStore) check(cs tls.ConnectionState, usage x509.ExtKeyUsage, dnsName string) error {
+	if len(cs.PeerCertificates) == 0 {
+		return errors.New("peer sent no certificate")
+	}
+	leaf := cs.PeerCertificates[0]
+	who := certIdentity(leaf)
+
+	now := time.Now()
+	if now.Before(leaf.NotBefore) {
+		return fmt.Errorf("certificate of %s is not valid until %s", who, leaf.NotBefore.Format(time.RFC3339))
+	}
+	if now.After(leaf.NotAfter) {
+		return fmt.Errorf("certificate of %s expired on %s", who, leaf.NotAfter.Format(time.RFC3339))
+	}
+
+	intermediates := x509.NewCertPool()
+	for _, c := range cs.PeerCertificates[1:] {
+		intermediates.AddCert(c)
+	}
+	chains, err := leaf.Verify(x509.VerifyOptions{
+		DNSName:       dnsName,
+		Roots:         t.roots,
+		Intermediates: intermediates,
+		KeyUsages:     []x509.ExtKeyUsage{usage},
+	})
+	if err != nil {
+		return fmt.Errorf("certificate of %s is not trusted: %w", who, err)
+	}
+
+	// Check every certificate in the chain against the lists of its issuer
+	chain := chains[0]
+	for i := 0; i+1 < len(chain); i++ {
+		if err := t.checkRevoked(chain[i], chain[i+1]); err != nil {
+			return fmt.Errorf("certificate of %s: %w", who, err)
+		}
+	}
+
+	return nil
+}
+
+// checkRevoked returns an error if cert is on a revocation list of issuer.
+func (t *trustStore) checkRevoked(cert *x509.Certificate, issuer *x509.Certificate) error {
+	for _, list := range t.crls {
+		if !bytes.Equal(list.RawIssuer, issuer.RawSubject) {
+			continue
+		}
+		if err := list.CheckSignatureFrom(issuer); err != nil {
+			return fmt.Errorf("revocation list of %s is not signed by it: %w", issuer.Subject.CommonName, err)
+		}
+		if !list.NextUpdate.IsZero() && time.Now().After(list.NextUpdate) {
+			return fmt.Errorf("revocation list of %s is out of date since %s", issuer.Subject.CommonName, list.NextUpdate.Format(time.RFC3339))
+		}
+
+		for _, rc := range list.RevokedCertificates {
+			if rc.SerialNumber.Cmp(cert.SerialNumber) == 0 {
+				return fmt.Errorf("serial %x was revoked on %s", cert.SerialNumber, rc.RevocationTime.Format(time.RFC3339))
+			}
+		}
+	}
+
+	return nil
+}
+
+// certIdentity returns the player a certificate belongs to, in canonical form:
+// its common name, or else its first DNS name other than localhost.
+func certIdentity(cert *x509.Certificate) string {
+	if cert.Subject.CommonName != "" {
+		return canonical(cert.Subject.CommonName)
+	}
+	for _, n := range cert.DNSNames {
+		if n != "localhost" {
+			return canonical(n)
+		}
+	}
+
+	return ""
+}
+
+// peerIdentity returns the player named by the verified certificate of the
+// peer of a connection.
+func peerIdentity(ctx context.Context) (string, error) {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return "", errors.New("no peer")
+	}
+	// Connections are only established once trustStore.verify accepts the
+	// peer certificate
+	info, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(info.State.PeerCertificates) == 0 {
+		return "", errors.New("peer has no verified certificate")
+	}
+
+	identity := certIdentity(info.State.PeerCertificates[0])
+	if identity == "" {
+		return "", errors.New("peer certificate names no player")
+	}
+
+	return identity, nil
+}
+
+// checkPeer checks that the peer of a connection is the given player.
+func checkPeer(ctx context.Context, player string) error {
+	identity, err := peerIdentity(ctx)
+	if err != nil {
+		return err
+	}
+	if identity != canonical(player) {
+		return fmt.Errorf("certificate of %s does not belong to %s", identity, player)
+	}
+
+	return nil
+}
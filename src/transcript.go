@@ -0,0 +1,526 @@
+package main
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/samsapti/sec1-handin-02/commit"
+	"github.com/samsapti/sec1-handin-02/dice"
+	pb "github.com/samsapti/sec1-handin-02/grpc"
+	"github.com/samsapti/sec1-handin-02/pedersen"
+	"google.golang.org/protobuf/proto"
+)
+
+// Kinds of transcript entries
+const (
+	entryStart      string = "start"
+	entryCommitment string = "commitment"
+	entryThrow      string = "throw"
+	entryOpening    string = "opening"
+	entryAck        string = "ack"
+	entryVerdict    string = "verdict"
+	entryValidation string = "validation"
+	entryResult     string = "result"
+	entryForfeit    string = "forfeit"
+	entryProofCheck string = "proof-check"
+)
+
+// transcriptEntry is a line of a transcript. Each entry is signed by the
+// player who wrote the transcript, and holds the hash of the line before it.
+type transcriptEntry struct {
+	Seq     uint64    `json:"seq"`
+	Time    time.Time `json:"time"`
+	Player  string    `json:"player"`
+	Session string    `json:"session"`
+	Kind    string    `json:"kind"`
+	Round   uint32    `json:"round,omitempty"`
+	From    string    `json:"from,omitempty"` // Who sent the message, or whose opening was validated
+
+	// Game settings and our certificate, in the start entry
+	Group   string   `json:"group,omitempty"`
+	Seed    []byte   `json:"seed,omitempty"`
+	Scheme  string   `json:"scheme,omitempty"`
+	Sides   uint64   `json:"sides,omitempty"`
+	Mode    string   `json:"mode,omitempty"`
+	Players []string `json:"players,omitempty"`
+	Cert    []byte   `json:"cert,omitempty"`
+
+	// Message contents, validation results and final values
+	C          []byte   `json:"c,omitempty"`
+	RangeProof []byte   `json:"range_proof,omitempty"`     // Marshalled protobuf
+	Knowledge  []byte   `json:"knowledge_proof,omitempty"` // Marshalled protobuf
+	Proof      string   `json:"proof,omitempty"`           // Which proof was checked
+	M          []byte   `json:"m,omitempty"`
+	R          []byte   `json:"r,omitempty"`
+	Value      *uint64  `json:"value,omitempty"`
+	Ok         *bool    `json:"ok,omitempty"`
+	Cheaters   []string `json:"cheaters,omitempty"`
+	Digest     []byte   `json:"digest,omitempty"`
+	Reason     string   `json:"reason,omitempty"`
+
+	Prev string `json:"prev"`
+	Sig  []byte `json:"sig,omitempty"`
+}
+
+// transcript writes a transcript of a session. A nil transcript writes
+// nothing.
+type transcript struct {
+	mu      sync.Mutex
+	f       *os.File
+	cert    *tls.Certificate
+	session string
+	seq     uint64
+	prev    string
+}
+
+// openTranscript creates the transcript of a session in dir, signed with the
+// key of cert. An empty dir disables it.
+func openTranscript(dir string, session []byte, cert *tls.Certificate) (*transcript, error) {
+	if dir == "" {
+		return nil, nil
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	id := hex.EncodeToString(session)
+	path := filepath.Join(dir, fmt.Sprintf("%s-%s.jsonl", strings.ToLower(*name), id[:16]))
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return nil, err
+	}
+
+	return &transcript{f: f, cert: cert, session: id}, nil
+}
+
+// start records the settings of the game, and our certificate to verify the
+// signatures with.
+func (t *transcript) start(params *pedersen.Params, scheme commit.Scheme, sides uint64, players []string) {
+	t.write(&transcriptEntry{
+		Kind:    entryStart,
+		Group:   params.Group.Name(),
+		Seed:    params.Seed,
+		Scheme:  scheme.Name(),
+		Sides:   sides,
+		Mode:    *mode,
+		Players: players,
+		Cert:    t.certificate(),
+	})
+}
+
+func (t *transcript) certificate() []byte {
+	if t == nil {
+		return nil
+	}
+	return t.cert.Certificate[0]
+}
+
+// message records a game message from a player, which may be us.
+func (t *transcript) message(from string, msg *pb.GameMessage) {
+	e := &transcriptEntry{From: from}
+	switch in := msg.Msg.(type) {
+	case *pb.GameMessage_Commitment:
+		e.Kind, e.Round, e.C = entryCommitment, in.Commitment.Round, in.Commitment.C
+		if in.Commitment.RangeProof != nil {
+			e.RangeProof, _ = proto.Marshal(in.Commitment.RangeProof)
+		}
+		if in.Commitment.Proof != nil {
+			e.Knowledge, _ = proto.Marshal(in.Commitment.Proof)
+		}
+	case *pb.GameMessage_Throw:
+		e.Kind, e.Round, e.Value = entryThrow, in.Throw.Round, &in.Throw.Val
+	case *pb.GameMessage_Opening:
+		e.Kind, e.Round, e.M, e.R = entryOpening, in.Opening.Round, in.Opening.M, in.Opening.R
+	case *pb.GameMessage_Ack:
+		e.Kind, e.Round, e.Ok = entryAck, in.Ack.Round, &in.Ack.Ack
+	case *pb.GameMessage_Verdict:
+		e.Kind, e.Round, e.Cheaters, e.Digest = entryVerdict, in.Verdict.Round, in.Verdict.Cheaters, in.Verdict.Digest
+	default:
+		return
+	}
+
+	t.write(e)
+}
+
+// validation records whether the opening of a player validated.
+func (t *transcript) validation(round uint32, from string, err error) {
+	ok := err == nil
+	e := &transcriptEntry{Kind: entryValidation, Round: round, From: from, Ok: &ok}
+	if err != nil {
+		e.Reason = err.Error()
+	}
+
+	t.write(e)
+}
+
+// proofCheck records whether a proof that goes with the commitment of a
+// player verified.
+func (t *transcript) proofCheck(round uint32, from string, proof string, err error) {
+	ok := err == nil
+	e := &transcriptEntry{Kind: entryProofCheck, Round: round, From: from, Proof: proof, Ok: &ok}
+	if err != nil {
+		e.Reason = err.Error()
+	}
+
+	t.write(e)
+}
+
+func (t *transcript) result(round uint32, value uint64) {
+	t.write(&transcriptEntry{Kind: entryResult, Round: round, Value: &value})
+}
+
+func (t *transcript) forfeit(round uint32, player string, reason string) {
+	t.write(&transcriptEntry{Kind: entryForfeit, Round: round, From: player, Reason: reason})
+}
+
+// write signs e, chains it to the previous entry and appends it. The file is
+// synced after every entry, so that it survives a crash.
+func (t *transcript) write(e *transcriptEntry) {
+	if t == nil {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	e.Seq = t.seq
+	e.Time = time.Now().UTC()
+	e.Player = *name
+	e.Session = t.session
+	e.Prev = t.prev
+
+	line, err := signEntry(e, t.cert.PrivateKey.(crypto.Signer))
+	if err == nil {
+		_, err = t.f.Write(append(line, '\n'))
+	}
+	if err == nil {
+		err = t.f.Sync()
+	}
+	if err != nil {
+		log.Fatalf("Error: cannot write transcript: %s\n", err)
+	}
+
+	t.seq++
+	t.prev = lineHash(line)
+}
+
+func (t *transcript) close() {
+	if t != nil {
+		t.f.Close()
+	}
+}
+
+// signEntry signs e without its signature, and returns the signed line.
+func signEntry(e *transcriptEntry, key crypto.Signer) ([]byte, error) {
+	e.Sig = nil
+	data, err := json.Marshal(e)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, ok := key.(ed25519.PrivateKey); ok {
+		e.Sig, err = key.Sign(rand.Reader, data, crypto.Hash(0))
+	} else {
+		digest := sha256.Sum256(data)
+		e.Sig, err = key.Sign(rand.Reader, digest[:], crypto.SHA256)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(e)
+}
+
+// checkEntrySig checks the signature of e by the owner of cert.
+func checkEntrySig(e transcriptEntry, cert *x509.Certificate) error {
+	sig := e.Sig
+	e.Sig = nil
+	data, err := json.Marshal(&e)
+	if err != nil {
+		return err
+	}
+
+	var algo x509.SignatureAlgorithm
+	switch cert.PublicKey.(type) {
+	case *ecdsa.PublicKey:
+		algo = x509.ECDSAWithSHA256
+	case *rsa.PublicKey:
+		algo = x509.SHA256WithRSA
+	case ed25519.PublicKey:
+		algo = x509.PureEd25519
+	default:
+		return errors.New("unsupported key type")
+	}
+
+	return cert.CheckSignature(algo, data, sig)
+}
+
+func lineHash(line []byte) string {
+	h := sha256.Sum256(line)
+	return hex.EncodeToString(h[:])
+}
+
+// runVerifyTranscript runs the verify-transcript subcommand:
+//
+//	dicegame verify-transcript [-trust certs/ca.cert.pem] file...
+func runVerifyTranscript(args []string) {
+	fs := flag.NewFlagSet("verify-transcript", flag.ExitOnError)
+	trustFiles := fs.String("trust", "certs/ca.cert.pem", "Comma-separated files or glob patterns of trusted CA certificates. Empty to not check who signed the transcript")
+	fs.Parse(args)
+	if fs.NArg() == 0 {
+		log.Fatalf("Usage: dicegame verify-transcript [-trust files] file...\n")
+	}
+
+	var roots *x509.CertPool
+	if *trustFiles != "" {
+		t, err := loadTrust(*trustFiles, "")
+		if err != nil {
+			log.Fatalf("Error: %s\n", err)
+		}
+		roots = t.roots
+	}
+
+	failed := false
+	for _, file := range fs.Args() {
+		summary, err := verifyTranscript(file, roots)
+		if err != nil {
+			log.Printf("%s: FAILED: %s\n", file, err)
+			failed = true
+			continue
+		}
+		log.Printf("%s: OK: %s\n", file, summary)
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// complete returns an error if e lacks a field that its kind needs.
+func (e *transcriptEntry) complete() error {
+	switch e.Kind {
+	case entryThrow, entryResult:
+		if e.Value == nil {
+			return fmt.Errorf("%s entry is missing value", e.Kind)
+		}
+	case entryAck, entryValidation, entryProofCheck:
+		if e.Ok == nil {
+			return fmt.Errorf("%s entry is missing ok", e.Kind)
+		}
+	}
+
+	return nil
+}
+
+// proofKey identifies a proof that went with a commitment.
+type proofKey struct {
+	round uint32
+	from  string
+	proof string
+}
+
+// verifyTranscript checks the chain and signatures of a transcript, that the
+// signer's certificate chains to roots (if given), and re-validates every
+// opening against its commitment. It also recomputes the result of every
+// round from the contributions.
+func verifyTranscript(file string, roots *x509.CertPool) (string, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return "", err
+	}
+
+	var (
+		start       transcriptEntry
+		cert        *x509.Certificate
+		scheme      commit.Scheme
+		session     []byte
+		prev        string
+		commitments = map[uint32]map[string][]byte{}
+		valid       = map[uint32]map[string]error{}
+		checked     = map[proofKey]error{}
+		values      = map[uint32][]uint64{}
+		results     = []string{}
+	)
+
+	lines := bytes.Split(bytes.TrimRight(data, "\n"), []byte("\n"))
+	for i, line := range lines {
+		var e transcriptEntry
+		if err := json.Unmarshal(line, &e); err != nil {
+			return "", fmt.Errorf("line %d: %w", i+1, err)
+		}
+
+		// Chain and signature
+		if e.Seq != uint64(i) {
+			return "", fmt.Errorf("line %d: sequence number %d", i+1, e.Seq)
+		}
+		if e.Prev != prev {
+			return "", fmt.Errorf("line %d: does not follow line %d", i+1, i)
+		}
+		prev = lineHash(line)
+
+		if i == 0 {
+			if start, cert, scheme, session, err = verifyStart(e, roots); err != nil {
+				return "", fmt.Errorf("line 1: %w", err)
+			}
+		} else if e.Player != start.Player || e.Session != start.Session {
+			return "", fmt.Errorf("line %d: entry of %s in session %s", i+1, e.Player, e.Session)
+		}
+		if err := checkEntrySig(e, cert); err != nil {
+			return "", fmt.Errorf("line %d: bad signature: %w", i+1, err)
+		}
+
+		// Contents. The entries are signed by the player whose transcript
+		// it is, who may have left out fields to trip us up.
+		if err := e.complete(); err != nil {
+			return "", fmt.Errorf("line %d: %w", i+1, err)
+		}
+		switch e.Kind {
+		case entryCommitment:
+			if commitments[e.Round] == nil {
+				commitments[e.Round] = map[string][]byte{}
+			}
+			commitments[e.Round][e.From] = e.C
+
+			c := &pb.Commitment{C: e.C}
+			if e.RangeProof != nil {
+				c.RangeProof = &pb.RangeProof{}
+				if err := proto.Unmarshal(e.RangeProof, c.RangeProof); err != nil {
+					return "", fmt.Errorf("line %d: %w", i+1, err)
+				}
+			}
+			if e.Knowledge != nil {
+				c.Proof = &pb.Proof{}
+				if err := proto.Unmarshal(e.Knowledge, c.Proof); err != nil {
+					return "", fmt.Errorf("line %d: %w", i+1, err)
+				}
+			}
+			err := checkProofs(scheme, c, start.Sides, roundContext(session, e.Round, e.From), func(proof string, err error) {
+				checked[proofKey{e.Round, e.From, proof}] = err
+			})
+			if err != nil && e.From == start.Player {
+				return "", fmt.Errorf("line %d: own commitment in round %d: %w", i+1, e.Round, err)
+			}
+
+		case entryProofCheck:
+			err, ok := checked[proofKey{e.Round, e.From, e.Proof}]
+			if !ok {
+				return "", fmt.Errorf("line %d: %s proof of %s without a commitment", i+1, e.Proof, e.From)
+			}
+			if *e.Ok != (err == nil) {
+				return "", fmt.Errorf("line %d: recorded %s proof of %s in round %d as %t, but it is %t", i+1, e.Proof, e.From, e.Round, *e.Ok, err == nil)
+			}
+			if err != nil {
+				results = append(results, fmt.Sprintf("%s caught cheating", e.From))
+			}
+
+		case entryThrow:
+			values[e.Round] = append(values[e.Round], *e.Value)
+
+		case entryOpening:
+			c, ok := commitments[e.Round][e.From]
+			if !ok {
+				return "", fmt.Errorf("line %d: opening of %s without a commitment", i+1, e.From)
+			}
+			err := checkOpening(scheme, start.Sides, session, e.Round, e.From, c, e.M, e.R)
+			if err != nil && e.From == start.Player {
+				return "", fmt.Errorf("line %d: own opening in round %d: %w", i+1, e.Round, err)
+			}
+			if valid[e.Round] == nil {
+				valid[e.Round] = map[string]error{}
+			}
+			valid[e.Round][e.From] = err
+			if err == nil {
+				values[e.Round] = append(values[e.Round], new(big.Int).SetBytes(e.M).Uint64())
+			}
+
+		case entryValidation:
+			err, ok := valid[e.Round][e.From]
+			if !ok {
+				return "", fmt.Errorf("line %d: validation of %s without an opening", i+1, e.From)
+			}
+			if *e.Ok != (err == nil) {
+				return "", fmt.Errorf("line %d: recorded validation of %s in round %d as %t, but it is %t", i+1, e.From, e.Round, *e.Ok, err == nil)
+			}
+			if err != nil {
+				results = append(results, fmt.Sprintf("%s caught cheating", e.From))
+			}
+
+		case entryResult:
+			if want := dice.Combine(start.Sides, values[e.Round]...); *e.Value != want {
+				return "", fmt.Errorf("line %d: result of round %d is %d, but the contributions give %d", i+1, e.Round, *e.Value, want)
+			}
+			results = append(results, fmt.Sprint(*e.Value))
+
+		case entryForfeit:
+			results = append(results, fmt.Sprintf("forfeited by %s", e.From))
+		}
+	}
+
+	if len(results) == 0 {
+		results = append(results, "none")
+	}
+
+	return fmt.Sprintf("%d entries by %s, results: %s", len(lines), start.Player, strings.Join(results, ", ")), nil
+}
+
+// verifyStart checks the first entry of a transcript, and returns what the
+// rest is verified with.
+func verifyStart(e transcriptEntry, roots *x509.CertPool) (transcriptEntry, *x509.Certificate, commit.Scheme, []byte, error) {
+	if e.Kind != entryStart {
+		return e, nil, nil, nil, errors.New("transcript does not begin with a start entry")
+	}
+
+	cert, err := x509.ParseCertificate(e.Cert)
+	if err != nil {
+		return e, nil, nil, nil, err
+	}
+	if roots != nil {
+		opts := x509.VerifyOptions{
+			Roots:       roots,
+			CurrentTime: e.Time,
+			KeyUsages:   []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		}
+		if _, err := cert.Verify(opts); err != nil {
+			return e, nil, nil, nil, fmt.Errorf("signer is not trusted: %w", err)
+		}
+	}
+	if certIdentity(cert) != canonical(e.Player) {
+		return e, nil, nil, nil, fmt.Errorf("certificate of %s does not belong to %s", certIdentity(cert), e.Player)
+	}
+
+	if err := dice.Check(e.Sides); err != nil {
+		return e, nil, nil, nil, err
+	}
+	grp, err := pedersen.GroupByName(e.Group)
+	if err != nil {
+		return e, nil, nil, nil, err
+	}
+	scheme, err := commit.ByName(e.Scheme, pedersen.DeriveGenerators(e.Seed, grp))
+	if err != nil {
+		return e, nil, nil, nil, err
+	}
+	session, err := hex.DecodeString(e.Session)
+	if err != nil {
+		return e, nil, nil, nil, err
+	}
+
+	return e, cert, scheme, session, nil
+}
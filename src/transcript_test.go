@@ -0,0 +1,298 @@
+package main
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/samsapti/sec1-handin-02/commit"
+	"github.com/samsapti/sec1-handin-02/dice"
+	pb "github.com/samsapti/sec1-handin-02/grpc"
+	"github.com/samsapti/sec1-handin-02/pedersen"
+)
+
+const testSides uint64 = 6
+
+// testCert returns a self-signed certificate for alice, with its key.
+func testCert(t *testing.T) *tls.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "alice"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
+
+// commitTo commits player to m in a round, with the proofs that go with it.
+func commitTo(t *testing.T, scheme commit.Scheme, session []byte, round uint32, player string, m uint64) (*pb.Commitment, *pb.Opening) {
+	ctx := roundContext(session, round, player)
+	c, o, err := scheme.Commit(new(big.Int).SetUint64(m).Bytes(), ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	commitment := &pb.Commitment{C: c, SessionId: session, Round: round}
+	if err := prove(scheme, commitment, o, testSides, ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	return commitment, &pb.Opening{M: o.Msg, R: o.R, SessionId: session, Round: round}
+}
+
+// writeTranscript writes the transcript of a classic game of two rounds
+// between Alice and Bob, as Alice, and returns its path.
+func writeTranscript(t *testing.T, cert *tls.Certificate) string {
+	*name = "Alice"
+	dir := t.TempDir()
+	session := make([]byte, 32)
+	rand.Read(session)
+	pp := pedersen.DeriveGenerators([]byte("test"), pedersen.Ristretto255())
+	scheme := commit.Pedersen(pp)
+
+	tr, err := openTranscript(dir, session, cert)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr.start(pp, scheme, testSides, []string{"Alice", "bob"})
+
+	// Round 1: Alice commits to 2, and Bob throws 3
+	c, o := commitTo(t, scheme, session, 1, "Alice", 2)
+	tr.message("Alice", &pb.GameMessage{Msg: &pb.GameMessage_Commitment{Commitment: c}})
+	tr.message("bob", &pb.GameMessage{Msg: &pb.GameMessage_Throw{Throw: &pb.DieThrow{Val: 3, Round: 1}}})
+	tr.message("Alice", &pb.GameMessage{Msg: &pb.GameMessage_Opening{Opening: o}})
+	tr.message("bob", &pb.GameMessage{Msg: &pb.GameMessage_Ack{Ack: &pb.Acknowledgement{Ack: true, Round: 1}}})
+	tr.result(1, dice.Combine(testSides, 2, 3))
+
+	// Round 2: Bob commits to 5, and Alice throws 0
+	c, o = commitTo(t, scheme, session, 2, "bob", 5)
+	tr.message("bob", &pb.GameMessage{Msg: &pb.GameMessage_Commitment{Commitment: c}})
+	tr.proofCheck(2, "bob", proofKnowledge, nil)
+	tr.proofCheck(2, "bob", proofRange, nil)
+	tr.message("Alice", &pb.GameMessage{Msg: &pb.GameMessage_Throw{Throw: &pb.DieThrow{Val: 0, Round: 2}}})
+	tr.message("bob", &pb.GameMessage{Msg: &pb.GameMessage_Opening{Opening: o}})
+	tr.validation(2, "bob", nil)
+	tr.message("Alice", &pb.GameMessage{Msg: &pb.GameMessage_Ack{Ack: &pb.Acknowledgement{Ack: true, Round: 2}}})
+	tr.result(2, dice.Combine(testSides, 5, 0))
+	tr.close()
+
+	files, err := filepath.Glob(filepath.Join(dir, "alice-*.jsonl"))
+	if err != nil || len(files) != 1 {
+		t.Fatalf("transcript not found: %v", err)
+	}
+
+	return files[0]
+}
+
+func readEntries(t *testing.T, file string) []transcriptEntry {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var entries []transcriptEntry
+	for _, line := range bytes.Split(bytes.TrimRight(data, "\n"), []byte("\n")) {
+		var e transcriptEntry
+		if err := json.Unmarshal(line, &e); err != nil {
+			t.Fatal(err)
+		}
+		entries = append(entries, e)
+	}
+
+	return entries
+}
+
+// rewrite chains and signs entries again into a new transcript, after edit
+// has changed each of them, so that only the edit is wrong.
+func rewrite(t *testing.T, entries []transcriptEntry, key crypto.Signer, edit func(i int, e *transcriptEntry)) string {
+	var out []byte
+	prev := ""
+	for i, e := range entries {
+		e.Prev = prev
+		edit(i, &e)
+		line, err := signEntry(&e, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		out = append(out, append(line, '\n')...)
+		prev = lineHash(line)
+	}
+
+	file := filepath.Join(t.TempDir(), "edited.jsonl")
+	if err := os.WriteFile(file, out, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	return file
+}
+
+// find returns the index of the first entry of a kind.
+func find(entries []transcriptEntry, kind string) int {
+	for i, e := range entries {
+		if e.Kind == kind {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func TestVerifyTranscript(t *testing.T) {
+	cert := testCert(t)
+	file := writeTranscript(t, cert)
+
+	summary, err := verifyTranscript(file, nil)
+	if err != nil {
+		t.Fatalf("valid transcript: %s", err)
+	}
+	if want := "results: 6, 6"; !strings.HasSuffix(summary, want) {
+		t.Errorf("summary %q does not end in %q", summary, want)
+	}
+
+	// Unchanged, the rewritten transcript still verifies
+	key := cert.PrivateKey.(crypto.Signer)
+	entries := readEntries(t, file)
+	if _, err := verifyTranscript(rewrite(t, entries, key, func(int, *transcriptEntry) {}), nil); err != nil {
+		t.Errorf("rewritten transcript: %s", err)
+	}
+
+	// Trusted and untrusted signers
+	x509Cert, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	roots := x509.NewCertPool()
+	roots.AddCert(x509Cert)
+	if _, err := verifyTranscript(file, roots); err != nil {
+		t.Errorf("transcript signed by a trusted player: %s", err)
+	}
+	if _, err := verifyTranscript(file, x509.NewCertPool()); err == nil {
+		t.Error("transcript signed by an untrusted player verifies")
+	}
+}
+
+func TestVerifyTranscriptTampered(t *testing.T) {
+	cert := testCert(t)
+	key := cert.PrivateKey.(crypto.Signer)
+	entries := readEntries(t, writeTranscript(t, cert))
+	result := find(entries, entryResult)
+	ack := find(entries, entryAck)
+	throw := find(entries, entryThrow)
+	validation := find(entries, entryValidation)
+
+	tests := []struct {
+		name string
+		edit func(i int, e *transcriptEntry)
+		want string
+	}{
+		{"broken chain", func(i int, e *transcriptEntry) {
+			if i == 3 {
+				e.Prev = lineHash([]byte("another line"))
+			}
+		}, "does not follow"},
+		{"reordered entries", func(i int, e *transcriptEntry) {
+			// Swapped, but chained again, so only the sequence numbers
+			// are wrong
+			if i == 2 || i == 3 {
+				prev := e.Prev
+				*e = entries[5-i]
+				e.Prev = prev
+			}
+		}, "line 3: sequence number 3"},
+		{"wrong result", func(i int, e *transcriptEntry) {
+			if i == result {
+				v := *e.Value + 1
+				e.Value = &v
+			}
+		}, "result of round 1"},
+		{"result without value", func(i int, e *transcriptEntry) {
+			if i == result {
+				e.Value = nil
+			}
+		}, "missing value"},
+		{"throw without value", func(i int, e *transcriptEntry) {
+			if i == throw {
+				e.Value = nil
+			}
+		}, "missing value"},
+		{"ack without ok", func(i int, e *transcriptEntry) {
+			if i == ack {
+				e.Ok = nil
+			}
+		}, "missing ok"},
+		{"validation without ok", func(i int, e *transcriptEntry) {
+			if i == validation {
+				e.Ok = nil
+			}
+		}, "missing ok"},
+		{"wrong validation", func(i int, e *transcriptEntry) {
+			if i == validation {
+				ok := false
+				e.Ok = &ok
+			}
+		}, "recorded validation of bob"},
+	}
+	for _, tt := range tests {
+		_, err := verifyTranscript(rewrite(t, entries, key, tt.edit), nil)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: got %v, want an error containing %q", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestVerifyTranscriptBadSignature(t *testing.T) {
+	cert := testCert(t)
+	file := writeTranscript(t, cert)
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lines := bytes.Split(bytes.TrimRight(data, "\n"), []byte("\n"))
+	var e transcriptEntry
+	if err := json.Unmarshal(lines[2], &e); err != nil {
+		t.Fatal(err)
+	}
+	e.Sig[len(e.Sig)-1] ^= 1
+	if lines[2], err = json.Marshal(&e); err != nil {
+		t.Fatal(err)
+	}
+
+	bad := filepath.Join(t.TempDir(), "bad.jsonl")
+	if err := os.WriteFile(bad, append(bytes.Join(lines, []byte("\n")), '\n'), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := verifyTranscript(bad, nil); err == nil || !strings.Contains(err.Error(), "line 3: bad signature") {
+		t.Errorf("got %v, want a bad signature on line 3", err)
+	}
+
+	// Signed with another key
+	other := testCert(t)
+	if _, err := verifyTranscript(rewrite(t, readEntries(t, file), other.PrivateKey.(crypto.Signer), func(int, *transcriptEntry) {}), nil); err == nil {
+		t.Error("transcript signed with another key than its certificate verifies")
+	}
+}
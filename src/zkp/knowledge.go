@@ -0,0 +1,61 @@
+package zkp
+
+import (
+	"math/big"
+
+	"github.com/samsapti/sec1-handin-02/pedersen"
+)
+
+// Domain separation label for proofs of knowledge
+const knowledgeLabel string = "sec1-handin-02/zkp/knowledge/"
+
+// KnowledgeProof shows that the prover knows an opening (m, r) of a
+// commitment c = g^m * h^r, without revealing it. It is a Schnorr proof for
+// the representation of c in g and h. The first message a = g^s * h^t is not
+// sent, since the verifier recomputes it from the challenge and responses.
+type KnowledgeProof struct {
+	E  *big.Int // Challenge
+	Zm *big.Int // Response for m: s + e*m
+	Zr *big.Int // Response for r: t + e*r
+}
+
+// ProveKnowledge proves that we know the opening o of c. The commitment must
+// not be bound; see pedersen.Unbind.
+func ProveKnowledge(pp *pedersen.Params, c pedersen.Element, o pedersen.Opening, ctx []byte) *KnowledgeProof {
+	q := pp.Group.Order()
+	s, t := pp.GetR(), pp.GetR()
+	a := pp.GetCommitment(s, t)
+
+	e := knowledgeChallenge(pp, c, ctx, a)
+	zm := new(big.Int).Mul(e, o.M)
+	zr := new(big.Int).Mul(e, o.R)
+
+	return &KnowledgeProof{
+		E:  e,
+		Zm: zm.Add(zm, s).Mod(zm, q),
+		Zr: zr.Add(zr, t).Mod(zr, q),
+	}
+}
+
+// Verify checks that the proof shows knowledge of an opening of c.
+func (p *KnowledgeProof) Verify(pp *pedersen.Params, c pedersen.Element, ctx []byte) error {
+	if !validScalar(pp, p.E) || !validScalar(pp, p.Zm) || !validScalar(pp, p.Zr) {
+		return ErrInvalid
+	}
+
+	// a = g^zm * h^zr / c^e
+	a := pp.Group.Add(pp.GetCommitment(p.Zm, p.Zr), pp.Group.Neg(pp.Group.ScalarMult(c, p.E)))
+	if knowledgeChallenge(pp, c, ctx, a).Cmp(p.E) != 0 {
+		return ErrInvalid
+	}
+
+	return nil
+}
+
+func knowledgeChallenge(pp *pedersen.Params, c pedersen.Element, ctx []byte, a pedersen.Element) *big.Int {
+	ch := newChallenger(pp, knowledgeLabel, ctx)
+	ch.element(c)
+	ch.element(a)
+
+	return ch.scalar()
+}
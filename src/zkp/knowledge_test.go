@@ -0,0 +1,72 @@
+package zkp
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/samsapti/sec1-handin-02/pedersen"
+)
+
+func TestKnowledgeProof(t *testing.T) {
+	ctx := []byte("ctx")
+	for _, grp := range testGroups {
+		pp := pedersen.DeriveGenerators([]byte("test"), grp)
+		for _, m := range []uint64{0, 1, 5} {
+			c, o := commitTo(pp, m)
+			if err := ProveKnowledge(pp, c, o, ctx).Verify(pp, c, ctx); err != nil {
+				t.Errorf("%s: proof for %d does not verify: %s", grp.Name(), m, err)
+			}
+		}
+	}
+}
+
+func TestKnowledgeProofBinding(t *testing.T) {
+	ctx := []byte("ctx")
+	for _, grp := range testGroups {
+		pp := pedersen.DeriveGenerators([]byte("test"), grp)
+		c, o := commitTo(pp, 3)
+		proof := ProveKnowledge(pp, c, o, ctx)
+
+		if err := proof.Verify(pp, c, []byte("other ctx")); err == nil {
+			t.Errorf("%s: proof verifies under another ctx", grp.Name())
+		}
+		if other, _ := commitTo(pp, 3); proof.Verify(pp, other, ctx) == nil {
+			t.Errorf("%s: proof verifies for another commitment", grp.Name())
+		}
+	}
+}
+
+func TestKnowledgeProofTampered(t *testing.T) {
+	ctx := []byte("ctx")
+	for _, grp := range testGroups {
+		pp := pedersen.DeriveGenerators([]byte("test"), grp)
+		q := grp.Order()
+		c, o := commitTo(pp, 2)
+		proof := ProveKnowledge(pp, c, o, ctx)
+
+		// Each of E, Zm and Zr, in a copy of the proof
+		field := []func(p *KnowledgeProof) **big.Int{
+			func(p *KnowledgeProof) **big.Int { return &p.E },
+			func(p *KnowledgeProof) **big.Int { return &p.Zm },
+			func(p *KnowledgeProof) **big.Int { return &p.Zr },
+		}
+		for i, f := range field {
+			p := *proof
+			k := f(&p)
+			*k = new(big.Int).Add(*k, big.NewInt(1))
+			(*k).Mod(*k, q)
+			if err := p.Verify(pp, c, ctx); err == nil {
+				t.Errorf("%s: proof with scalar %d tampered verifies", grp.Name(), i)
+			}
+
+			// Out of range, including the same scalar plus q
+			for _, bad := range []*big.Int{nil, big.NewInt(-1), q, new(big.Int).Add(*f(proof), q)} {
+				p := *proof
+				*f(&p) = bad
+				if err := p.Verify(pp, c, ctx); err != ErrInvalid {
+					t.Errorf("%s: proof with scalar %d = %v: got %v, want %v", grp.Name(), i, bad, err, ErrInvalid)
+				}
+			}
+		}
+	}
+}
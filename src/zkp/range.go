@@ -0,0 +1,97 @@
+package zkp
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/samsapti/sec1-handin-02/pedersen"
+)
+
+// Domain separation label for range proofs
+const rangeLabel string = "sec1-handin-02/zkp/range/"
+
+// RangeProof shows that a commitment c = g^m * h^r opens to some m in [0, n),
+// without revealing m. It is an OR of n proofs of knowledge of log_h(c / g^j),
+// one for each j in [0, n), of which only the one for j = m is real (Cramer,
+// Damgård and Schoenmakers). The challenges of all branches add up to the
+// Fiat–Shamir challenge, so the prover can simulate all but one of them.
+type RangeProof struct {
+	E []*big.Int // Challenge of each branch
+	Z []*big.Int // Response of each branch
+}
+
+// ProveRange proves that c, opened by o, commits to a value in [0, n). The
+// commitment must not be bound; see pedersen.Unbind.
+func ProveRange(pp *pedersen.Params, c pedersen.Element, o pedersen.Opening, n uint64, ctx []byte) (*RangeProof, error) {
+	if !o.M.IsUint64() || o.M.Uint64() >= n {
+		return nil, errors.New("committed value is out of range")
+	}
+	m := o.M.Uint64()
+
+	q := pp.Group.Order()
+	proof := &RangeProof{E: make([]*big.Int, n), Z: make([]*big.Int, n)}
+	commitments := make([]pedersen.Element, n)
+	sum := new(big.Int)
+
+	// Simulate every branch but the real one, with a made-up challenge
+	w := pp.GetR()
+	for j := uint64(0); j < n; j++ {
+		if j == m {
+			commitments[j] = pp.Group.ScalarMult(pp.H, w)
+			continue
+		}
+
+		proof.E[j], proof.Z[j] = pp.GetR(), pp.GetR()
+		commitments[j] = simulate(pp, c, j, proof.E[j], proof.Z[j])
+		sum.Add(sum, proof.E[j])
+	}
+
+	// The real branch gets what is left of the challenge
+	e := rangeChallenge(pp, c, n, ctx, commitments)
+	proof.E[m] = e.Sub(e, sum).Mod(e, q)
+	z := new(big.Int).Mul(proof.E[m], o.R)
+	proof.Z[m] = z.Add(z, w).Mod(z, q)
+
+	return proof, nil
+}
+
+// Verify checks that the proof shows that c commits to a value in [0, n).
+func (p *RangeProof) Verify(pp *pedersen.Params, c pedersen.Element, n uint64, ctx []byte) error {
+	if uint64(len(p.E)) != n || uint64(len(p.Z)) != n {
+		return errors.New("range proof has the wrong number of branches")
+	}
+
+	commitments := make([]pedersen.Element, n)
+	sum := new(big.Int)
+	for j := uint64(0); j < n; j++ {
+		if !validScalar(pp, p.E[j]) || !validScalar(pp, p.Z[j]) {
+			return ErrInvalid
+		}
+		commitments[j] = simulate(pp, c, j, p.E[j], p.Z[j])
+		sum.Add(sum, p.E[j])
+	}
+
+	if sum.Mod(sum, pp.Group.Order()).Cmp(rangeChallenge(pp, c, n, ctx, commitments)) != 0 {
+		return ErrInvalid
+	}
+
+	return nil
+}
+
+// simulate returns the first message h^z / (c / g^j)^e of branch j, which is
+// what an honest prover sent if the branch verifies.
+func simulate(pp *pedersen.Params, c pedersen.Element, j uint64, e *big.Int, z *big.Int) pedersen.Element {
+	y := pp.Group.Add(c, pp.Group.Neg(pp.Group.ScalarMult(pp.G, new(big.Int).SetUint64(j))))
+	return pp.Group.Add(pp.Group.ScalarMult(pp.H, z), pp.Group.Neg(pp.Group.ScalarMult(y, e)))
+}
+
+func rangeChallenge(pp *pedersen.Params, c pedersen.Element, n uint64, ctx []byte, commitments []pedersen.Element) *big.Int {
+	ch := newChallenger(pp, rangeLabel, ctx)
+	ch.element(c)
+	ch.uint(n)
+	for _, a := range commitments {
+		ch.element(a)
+	}
+
+	return ch.scalar()
+}
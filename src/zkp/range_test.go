@@ -0,0 +1,151 @@
+package zkp
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/samsapti/sec1-handin-02/pedersen"
+)
+
+var testGroups = []pedersen.Group{pedersen.ModP2048(), pedersen.Ristretto255()}
+
+// Number of sides of the die in the tests
+const testSides uint64 = 6
+
+// commitTo returns a commitment to m and its opening.
+func commitTo(pp *pedersen.Params, m uint64) (pedersen.Element, pedersen.Opening) {
+	o := pedersen.Opening{M: new(big.Int).SetUint64(m), R: pp.GetR()}
+	return pp.GetCommitment(o.M, o.R), o
+}
+
+// clone returns a copy of the proof that can be tampered with.
+func (p *RangeProof) clone() *RangeProof {
+	return &RangeProof{
+		E: append([]*big.Int{}, p.E...),
+		Z: append([]*big.Int{}, p.Z...),
+	}
+}
+
+func TestRangeProof(t *testing.T) {
+	ctx := []byte("ctx")
+	for _, grp := range testGroups {
+		pp := pedersen.DeriveGenerators([]byte("test"), grp)
+		for m := uint64(0); m < testSides; m++ {
+			c, o := commitTo(pp, m)
+			proof, err := ProveRange(pp, c, o, testSides, ctx)
+			if err != nil {
+				t.Fatalf("%s: prove %d: %s", grp.Name(), m, err)
+			}
+			if err := proof.Verify(pp, c, testSides, ctx); err != nil {
+				t.Errorf("%s: proof for %d does not verify: %s", grp.Name(), m, err)
+			}
+		}
+	}
+}
+
+func TestProveRangeOutOfRange(t *testing.T) {
+	for _, grp := range testGroups {
+		pp := pedersen.DeriveGenerators([]byte("test"), grp)
+		q := grp.Order()
+		for _, m := range []*big.Int{
+			new(big.Int).SetUint64(testSides),
+			new(big.Int).Add(q, big.NewInt(1)),
+		} {
+			o := pedersen.Opening{M: m, R: pp.GetR()}
+			c := pp.GetCommitment(o.M, o.R)
+			if _, err := ProveRange(pp, c, o, testSides, nil); err == nil {
+				t.Errorf("%s: proves that %s is in [0, %d)", grp.Name(), m, testSides)
+			}
+		}
+	}
+}
+
+func TestRangeProofBinding(t *testing.T) {
+	ctx := []byte("ctx")
+	for _, grp := range testGroups {
+		pp := pedersen.DeriveGenerators([]byte("test"), grp)
+		c, o := commitTo(pp, 3)
+		proof, err := ProveRange(pp, c, o, testSides, ctx)
+		if err != nil {
+			t.Fatalf("%s: prove: %s", grp.Name(), err)
+		}
+
+		if err := proof.Verify(pp, c, testSides, []byte("other ctx")); err == nil {
+			t.Errorf("%s: proof verifies under another ctx", grp.Name())
+		}
+		if other, _ := commitTo(pp, 3); proof.Verify(pp, other, testSides, ctx) == nil {
+			t.Errorf("%s: proof verifies for another commitment", grp.Name())
+		}
+		for _, n := range []uint64{testSides - 1, testSides + 1} {
+			if err := proof.Verify(pp, c, n, ctx); err == nil {
+				t.Errorf("%s: proof verifies for n = %d", grp.Name(), n)
+			}
+		}
+	}
+}
+
+func TestRangeProofTampered(t *testing.T) {
+	ctx := []byte("ctx")
+	one := big.NewInt(1)
+	for _, grp := range testGroups {
+		pp := pedersen.DeriveGenerators([]byte("test"), grp)
+		q := grp.Order()
+		c, o := commitTo(pp, 2)
+		proof, err := ProveRange(pp, c, o, testSides, ctx)
+		if err != nil {
+			t.Fatalf("%s: prove: %s", grp.Name(), err)
+		}
+
+		// Both the real branch and a simulated one
+		for _, j := range []int{2, 4} {
+			p := proof.clone()
+			p.E[j] = new(big.Int).Add(p.E[j], one)
+			p.E[j].Mod(p.E[j], q)
+			if err := p.Verify(pp, c, testSides, ctx); err == nil {
+				t.Errorf("%s: proof with E[%d] tampered verifies", grp.Name(), j)
+			}
+
+			p = proof.clone()
+			p.Z[j] = new(big.Int).Add(p.Z[j], one)
+			p.Z[j].Mod(p.Z[j], q)
+			if err := p.Verify(pp, c, testSides, ctx); err == nil {
+				t.Errorf("%s: proof with Z[%d] tampered verifies", grp.Name(), j)
+			}
+		}
+
+		// Moving challenge from one branch to another keeps the sum
+		p := proof.clone()
+		p.E[0] = new(big.Int).Add(p.E[0], one)
+		p.E[0].Mod(p.E[0], q)
+		p.E[1] = new(big.Int).Sub(p.E[1], one)
+		p.E[1].Mod(p.E[1], q)
+		if err := p.Verify(pp, c, testSides, ctx); err == nil {
+			t.Errorf("%s: proof with challenge moved between branches verifies", grp.Name())
+		}
+
+		p = proof.clone()
+		p.E, p.Z = p.E[:testSides-1], p.Z[:testSides-1]
+		if err := p.Verify(pp, c, testSides, ctx); err == nil {
+			t.Errorf("%s: proof with a branch missing verifies", grp.Name())
+		}
+		p = proof.clone()
+		p.Z = p.Z[:testSides-1]
+		if err := p.Verify(pp, c, testSides, ctx); err == nil {
+			t.Errorf("%s: proof with a response missing verifies", grp.Name())
+		}
+
+		for _, k := range []*big.Int{nil, big.NewInt(-1), q} {
+			p = proof.clone()
+			p.E[3] = k
+			if err := p.Verify(pp, c, testSides, ctx); err != ErrInvalid {
+				t.Errorf("%s: proof with E[3] = %v: got %v, want %v", grp.Name(), k, err, ErrInvalid)
+			}
+
+			p = proof.clone()
+			p.Z[3] = k
+			if err := p.Verify(pp, c, testSides, ctx); err != ErrInvalid {
+				t.Errorf("%s: proof with Z[3] = %v: got %v, want %v", grp.Name(), k, err, ErrInvalid)
+			}
+		}
+	}
+}
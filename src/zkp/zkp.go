@@ -0,0 +1,61 @@
+// Package zkp implements non-interactive zero-knowledge proofs about Pedersen
+// commitments. They are Sigma protocols made non-interactive with the
+// Fiat–Shamir transform, and every proof is bound to a context, such as a
+// session, round and player, so that it cannot be replayed elsewhere.
+package zkp
+
+import (
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"hash"
+	"math/big"
+
+	"github.com/samsapti/sec1-handin-02/pedersen"
+)
+
+// ErrInvalid is returned when a proof does not verify.
+var ErrInvalid error = errors.New("invalid proof")
+
+// challenger hashes what the verifier would otherwise send a random challenge
+// after: the statement, the parameters, the context and the prover's first
+// messages.
+type challenger struct {
+	pp *pedersen.Params
+	h  hash.Hash
+}
+
+func newChallenger(pp *pedersen.Params, label string, ctx []byte) *challenger {
+	c := &challenger{pp: pp, h: sha512.New()}
+	c.bytes([]byte(label))
+	c.bytes([]byte(pp.Group.Name()))
+	c.element(pp.G)
+	c.element(pp.H)
+	c.bytes(ctx)
+
+	return c
+}
+
+func (c *challenger) bytes(b []byte) {
+	binary.Write(c.h, binary.BigEndian, uint32(len(b)))
+	c.h.Write(b)
+}
+
+func (c *challenger) element(e pedersen.Element) {
+	c.bytes(c.pp.Group.Encode(e))
+}
+
+func (c *challenger) uint(x uint64) {
+	binary.Write(c.h, binary.BigEndian, x)
+}
+
+// scalar returns the challenge, reduced mod the group order.
+func (c *challenger) scalar() *big.Int {
+	e := new(big.Int).SetBytes(c.h.Sum(nil))
+	return e.Mod(e, c.pp.Group.Order())
+}
+
+// validScalar reports whether k is a reduced scalar of the group.
+func validScalar(pp *pedersen.Params, k *big.Int) bool {
+	return k != nil && k.Sign() >= 0 && k.Cmp(pp.Group.Order()) < 0
+}